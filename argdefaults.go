@@ -0,0 +1,103 @@
+package goo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ParseArgsWithConfig parses the arguments as ParseArgsFrom, defaulting the
+// flags tagged `config:"Path"` from the field at the path in the config, e.g.
+// `config:"Database.DSN"`. Zero values in the config are ignored.
+//
+// Flags read environment variables with go-arg's env tag, e.g.
+// `arg:"--dsn,env:APP_DSN"`. The precedence is flag > env > config >
+// `default` tag. The config values are shown as defaults in the help, and
+// don't satisfy required flags.
+func ParseArgsWithConfig[T any](args []string, cfg any) (*T, error) {
+	var o T
+
+	if cfg != nil {
+		err := setConfigDefaults(reflect.ValueOf(&o).Elem(), reflect.ValueOf(cfg))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// go-arg uses the non-zero field values as the defaults
+	err := parseArgs(args, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+// setConfigDefaults sets the fields tagged with a config path to the value at
+// the path in cfg.
+func setConfigDefaults(v reflect.Value, cfg reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fv := v.Field(i)
+		if field.Anonymous && fv.Kind() == reflect.Struct {
+			err := setConfigDefaults(fv, cfg)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		path := field.Tag.Get("config")
+		if path == "" {
+			continue
+		}
+
+		cv, ok := configField(cfg, path)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case cv.Type().AssignableTo(fv.Type()):
+			fv.Set(cv)
+		case cv.Type().ConvertibleTo(fv.Type()):
+			fv.Set(cv.Convert(fv.Type()))
+		default:
+			return fmt.Errorf("config %s: cannot use %s as %s", path, cv.Type(), fv.Type())
+		}
+	}
+
+	return nil
+}
+
+// configField returns the non-zero value at the dotted path of fields.
+func configField(v reflect.Value, path string) (reflect.Value, bool) {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+
+		v = v.FieldByName(name)
+		if !v.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+
+	if v.IsZero() {
+		return reflect.Value{}, false
+	}
+
+	return v, true
+}
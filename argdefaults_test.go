@@ -0,0 +1,59 @@
+package goo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArgsWithConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	type SyncCmd struct {
+		Verbose bool `arg:"-v"`
+		Force   bool `arg:"-f"`
+	}
+
+	type Args struct {
+		DSN      string        `arg:"--dsn,env:APP_DSN" config:"Database.DSN"`
+		LogLevel string        `arg:"--log-level,-l,env:APP_LOG_LEVEL" config:"Logging.LogLevel" default:"info"`
+		Timeout  time.Duration `arg:"--timeout,env:APP_TIMEOUT" default:"10s"`
+		DryRun   bool          `arg:"--dry-run,env:APP_DRY_RUN"`
+		Sync     *SyncCmd      `arg:"subcommand:sync"`
+	}
+
+	cfg := &Config{
+		Database: &DatabaseConfig{DSN: "config.db"},
+		Logging:  &LoggerConfig{LogLevel: "debug"},
+	}
+
+	t.Setenv("APP_DSN", "env.db")
+	t.Setenv("APP_DRY_RUN", "true")
+
+	// env > config > default tag
+	args, err := ParseArgsWithConfig[Args](nil, cfg)
+	assert.NoError(err)
+	assert.Equal(&Args{DSN: "env.db", LogLevel: "debug", Timeout: 10 * time.Second, DryRun: true}, args)
+
+	// flags > env, including the flags of subcommands
+	args, err = ParseArgsWithConfig[Args]([]string{"--dsn=flag.db", "-l", "warn", "--dry-run=false", "sync", "-v", "-f"}, cfg)
+	assert.NoError(err)
+	assert.Equal("flag.db", args.DSN)
+	assert.Equal("warn", args.LogLevel)
+	assert.False(args.DryRun)
+	assert.Equal(&SyncCmd{Verbose: true, Force: true}, args.Sync)
+
+	// invalid env values are argument errors
+	t.Setenv("APP_TIMEOUT", "soon")
+	_, err = ParseArgsWithConfig[Args](nil, cfg)
+	assert.ErrorContains(err, "APP_TIMEOUT")
+	assert.Equal(2, ExitCode(err))
+
+	type BadArgs struct {
+		Workers int `arg:"--workers" config:"Database.DSN"`
+	}
+
+	_, err = ParseArgsWithConfig[BadArgs](nil, cfg)
+	assert.EqualError(err, "config Database.DSN: cannot use string as int")
+}
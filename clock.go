@@ -0,0 +1,24 @@
+package goo
+
+import "time"
+
+// Clock is the time source of the Scheduler and Supervise, replaced by a fake
+// clock in tests (see gootest.FakeClock).
+type Clock interface {
+	Now() time.Time
+	// After sends the time on the returned channel after d.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock of the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
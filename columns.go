@@ -0,0 +1,222 @@
+package goo
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// NullColumn is a nullable T. Unlike sql.Null, NULL is marshaled to JSON as
+// null, rather than as {"V": ..., "Valid": false}.
+type NullColumn[T any] struct {
+	V     T
+	Valid bool
+}
+
+// NewNullColumn returns a valid NullColumn of v.
+func NewNullColumn[T any](v T) NullColumn[T] {
+	return NullColumn[T]{V: v, Valid: true}
+}
+
+func (n *NullColumn[T]) Scan(src any) error {
+	var null sql.Null[T]
+	err := null.Scan(src)
+	if err != nil {
+		return err
+	}
+
+	n.V, n.Valid = null.V, null.Valid
+	return nil
+}
+
+func (n NullColumn[T]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	if valuer, ok := any(n.V).(driver.Valuer); ok {
+		return valuer.Value()
+	}
+
+	return n.V, nil
+}
+
+// MarshalJSON
+func (n NullColumn[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(n.V)
+}
+
+// UnmarshalJSON
+func (n *NullColumn[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		var zero T
+		n.V, n.Valid = zero, false
+		return nil
+	}
+
+	err := json.Unmarshal(data, &n.V)
+	if err != nil {
+		return err
+	}
+
+	n.Valid = true
+	return nil
+}
+
+// StringSliceColumn stores []string as a JSON array. NULL is scanned as nil.
+type StringSliceColumn []string
+
+func (s *StringSliceColumn) Scan(src any) error {
+	*s = nil
+	return scanJSON(src, (*[]string)(s))
+}
+
+func (s StringSliceColumn) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+
+	return valueJSON([]string(s))
+}
+
+// MapColumn stores map[string]V as a JSON object. NULL is scanned as nil.
+type MapColumn[V any] map[string]V
+
+func (m *MapColumn[V]) Scan(src any) error {
+	*m = nil
+	return scanJSON(src, (*map[string]V)(m))
+}
+
+func (m MapColumn[V]) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+
+	return valueJSON(map[string]V(m))
+}
+
+func scanJSON(src any, v any) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(src, v)
+	case string:
+		return json.Unmarshal([]byte(src), v)
+	default:
+		return fmt.Errorf("unsupported type: %T", src)
+	}
+}
+
+func valueJSON(v any) (driver.Value, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+// DecimalColumn is an exact decimal, e.g. an amount of money. It's stored as
+// its decimal string, e.g. "12.30" is stored as "12.3", so that it isn't
+// rounded like a float. It's also marshaled to JSON as a string. The zero
+// value is 0.
+//
+// A NULL can't be scanned into a DecimalColumn, use NullColumn[DecimalColumn]
+// for nullable columns.
+type DecimalColumn struct {
+	rat *big.Rat
+}
+
+// NewDecimal returns the decimal of a copy of r.
+func NewDecimal(r *big.Rat) DecimalColumn {
+	return DecimalColumn{rat: new(big.Rat).Set(r)}
+}
+
+// ParseDecimal parses a decimal, e.g. "12.30" or "-1e3".
+func ParseDecimal(s string) (DecimalColumn, error) {
+	var d DecimalColumn
+	err := d.scanString(s)
+	return d, err
+}
+
+// Rat returns a copy of the decimal as a big.Rat.
+func (d DecimalColumn) Rat() *big.Rat {
+	if d.rat == nil {
+		return new(big.Rat)
+	}
+
+	return new(big.Rat).Set(d.rat)
+}
+
+// String formats the decimal with as many digits as it needs, or 18 digits
+// after the point if it doesn't terminate, e.g. 1/3.
+func (d DecimalColumn) String() string {
+	r := d.Rat()
+	prec, exact := r.FloatPrec()
+	if !exact {
+		prec = 18
+	}
+
+	return r.FloatString(prec)
+}
+
+func (d *DecimalColumn) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		return fmt.Errorf("decimal: cannot scan NULL, use NullColumn[DecimalColumn]")
+	case []byte:
+		return d.scanString(string(src))
+	case string:
+		return d.scanString(src)
+	case int64:
+		d.rat = new(big.Rat).SetInt64(src)
+	case float64:
+		// as the shortest decimal, e.g. 0.1 rather than its binary value
+		return d.scanString(strconv.FormatFloat(src, 'f', -1, 64))
+	default:
+		return fmt.Errorf("unsupported type: %T", src)
+	}
+
+	return nil
+}
+
+func (d *DecimalColumn) scanString(s string) error {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return fmt.Errorf("invalid decimal: %q", s)
+	}
+
+	d.rat = r
+	return nil
+}
+
+func (d DecimalColumn) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// MarshalJSON
+func (d DecimalColumn) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a string or a number.
+func (d *DecimalColumn) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		s = n.String()
+	}
+
+	return d.scanString(s)
+}
@@ -0,0 +1,123 @@
+package goo
+
+import (
+	"encoding/json"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type columnsRow struct {
+	ID       int64
+	Nickname NullColumn[string]
+	Age      NullColumn[int64]
+	Tags     StringSliceColumn
+	Meta     MapColumn[int]
+	Price    DecimalColumn
+}
+
+func TestColumns(t *testing.T) {
+	assert := assert.New(t)
+
+	db, err := sqlx.Open("sqlite3", filepath.Join(t.TempDir(), "app.db"))
+	assert.NoError(err)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, nickname TEXT, age INTEGER, tags TEXT, meta TEXT, price TEXT)")
+	assert.NoError(err)
+
+	price, err := ParseDecimal("19.90")
+	assert.NoError(err)
+
+	row := columnsRow{
+		Nickname: NewNullColumn("ally"),
+		Tags:     StringSliceColumn{"a", "b"},
+		Meta:     MapColumn[int]{"views": 3},
+		Price:    price,
+	}
+
+	_, err = db.NamedExec("INSERT INTO items (nickname, age, tags, meta, price) VALUES (:nickname, :age, :tags, :meta, :price)", row)
+	assert.NoError(err)
+
+	var got columnsRow
+	assert.NoError(db.Get(&got, "SELECT * FROM items"))
+	assert.Equal(NewNullColumn("ally"), got.Nickname)
+	assert.Equal(NullColumn[int64]{}, got.Age)
+	assert.Equal(StringSliceColumn{"a", "b"}, got.Tags)
+	assert.Equal(MapColumn[int]{"views": 3}, got.Meta)
+	assert.Equal("19.9", got.Price.String())
+
+	var raw struct {
+		Age   *int64
+		Tags  string
+		Price string
+	}
+	assert.NoError(db.Get(&raw, "SELECT age, tags, price FROM items"))
+	assert.Nil(raw.Age)
+	assert.Equal(`["a","b"]`, raw.Tags)
+	assert.Equal("19.9", raw.Price)
+
+	data, err := json.Marshal(got)
+	assert.NoError(err)
+	assert.JSONEq(`{"ID":1,"Nickname":"ally","Age":null,"Tags":["a","b"],"Meta":{"views":3},"Price":"19.9"}`, string(data))
+
+	var decoded columnsRow
+	assert.NoError(json.Unmarshal(data, &decoded))
+	assert.Equal(got.Nickname, decoded.Nickname)
+	assert.Equal(got.Age, decoded.Age)
+	assert.Equal(got.Tags, decoded.Tags)
+	assert.Equal(got.Meta, decoded.Meta)
+	assert.Equal("19.9", decoded.Price.String())
+}
+
+func TestDecimalColumn(t *testing.T) {
+	assert := assert.New(t)
+
+	var d DecimalColumn
+	assert.NoError(d.Scan(0.1))
+	assert.Equal("0.1", d.String())
+
+	assert.NoError(d.Scan(int64(42)))
+	assert.Equal("42", d.String())
+
+	assert.NoError(json.Unmarshal([]byte(`12.345`), &d))
+	assert.Equal("12.345", d.String())
+
+	assert.Error(d.Scan("twelve"))
+	assert.ErrorContains(d.Scan(nil), "use NullColumn[DecimalColumn]")
+
+	// copies don't share the value
+	c := d
+	assert.NoError(d.Scan(int64(1)))
+	assert.Equal("12.345", c.String())
+	assert.Equal("0", DecimalColumn{}.String())
+
+	var n NullColumn[DecimalColumn]
+	assert.NoError(n.Scan(nil))
+	assert.False(n.Valid)
+	assert.NoError(n.Scan("1.50"))
+	assert.Equal(NewNullColumn(NewDecimal(big.NewRat(3, 2))), n)
+
+	_, err := ParseDecimal("1.2.3")
+	assert.EqualError(err, `invalid decimal: "1.2.3"`)
+}
+
+func TestJSONColumnsScanNull(t *testing.T) {
+	assert := assert.New(t)
+
+	tags := StringSliceColumn{"a"}
+	assert.NoError(tags.Scan(nil))
+	assert.Nil(tags)
+
+	meta := MapColumn[int]{"views": 3}
+	assert.NoError(meta.Scan(nil))
+	assert.Nil(meta)
+
+	// scans replace the previous value, rather than merging into it
+	meta = MapColumn[int]{"views": 3}
+	assert.NoError(meta.Scan(`{"likes": 1}`))
+	assert.Equal(MapColumn[int]{"likes": 1}, meta)
+}
@@ -1,8 +1,11 @@
 package goo
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/alexflint/go-arg"
@@ -12,6 +15,10 @@ type Config struct {
 	Database *DatabaseConfig
 	Logging  *LoggerConfig
 	Echo     *EchoConfig
+	Systemd  *SystemdConfig
+	PIDFile  *PIDFileConfig
+	Debug    *DebugConfig
+	Tracing  *TracingConfig
 }
 
 func ParseArgs[T any]() (*T, error) {
@@ -24,9 +31,101 @@ func ParseArgs[T any]() (*T, error) {
 	return &o, nil
 }
 
+// ArgsError is a command line error returned by ParseArgsFrom. Err is
+// arg.ErrHelp or arg.ErrVersion if the user asked for the help or version, or
+// the parse error.
+type ArgsError struct {
+	Err error
+
+	parser *arg.Parser
+	dest   any
+}
+
+func (e *ArgsError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ArgsError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode is 0 for the help and version, and 2 for parse errors.
+func (e *ArgsError) ExitCode() int {
+	if errors.Is(e.Err, arg.ErrHelp) || errors.Is(e.Err, arg.ErrVersion) {
+		return 0
+	}
+
+	return 2
+}
+
+// Print writes the help or version to stdout, or the usage and the parse
+// error to stderr, as arg.MustParse does.
+func (e *ArgsError) Print() {
+	e.print(os.Stdout, os.Stderr)
+}
+
+func (e *ArgsError) print(stdout, stderr io.Writer) {
+	subcommand := e.parser.SubcommandNames()
+
+	switch {
+	case errors.Is(e.Err, arg.ErrHelp):
+		e.parser.WriteHelpForSubcommand(stdout, subcommand...)
+	case errors.Is(e.Err, arg.ErrVersion):
+		if v, ok := e.dest.(arg.Versioned); ok {
+			fmt.Fprintln(stdout, v.Version())
+		}
+	default:
+		e.parser.WriteUsageForSubcommand(stderr, subcommand...)
+		fmt.Fprintln(stderr, "error:", e.Err)
+	}
+}
+
+// ParseArgsFrom parses the arguments, e.g. os.Args[1:]. Unlike ParseArgs, it
+// doesn't exit: the help, version and parse errors are returned as an
+// ArgsError, which Main prints before exiting with its ExitCode.
+func ParseArgsFrom[T any](args []string) (*T, error) {
+	var o T
+
+	err := parseArgs(args, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+func parseArgs(args []string, dest any) error {
+	p, err := arg.NewParser(arg.Config{Program: filepath.Base(os.Args[0])}, dest)
+	if err != nil {
+		return err
+	}
+
+	err = p.Parse(args)
+	if err != nil {
+		return &ArgsError{Err: err, parser: p, dest: dest}
+	}
+
+	return nil
+}
+
 var ErrNoConfig = fmt.Errorf("no config is found")
 
+// ParseConfig decodes the config from {prefix}_CONFIG_JSON, _TOML or _YAML,
+// the file at {prefix}_CONFIG_FILE, or the http(s) URL or s3 URI at
+// {prefix}_CONFIG_URL. Remote configs are fetched with retries, authenticated
+// by {prefix}_CONFIG_URL_TOKEN, or _USERNAME and _PASSWORD, and cached at
+// {prefix}_CONFIG_CACHE, as a fallback when the source is down. Without these
+// variables, the config file is looked up with FindConfigFile, using the
+// lowercased prefix as the app name.
+//
+// The profile named by {prefix}_ENV, e.g. "prod", is merged over the defaults
+// from the "profiles" section of the config. Environment variables named
+// after the field path then override single fields, e.g. APP_DATABASE_DSN or
+// APP_LOGGING_LOGLEVEL. The `env` tag renames a field, and `env:"-"` skips it.
+// Values like "file:///run/secrets/db_password" are replaced with the content
+// of the file, except in fields tagged `secret:"-"`. Finally, the config is checked with ValidateConfig.
 func ParseConfig[T any](prefix string) (*T, error) {
+	appName := strings.ToLower(prefix)
 	prefix = strings.ToUpper(prefix)
 
 	var o T
@@ -38,21 +137,145 @@ func ParseConfig[T any](prefix string) (*T, error) {
 		prefix = prefix + "_"
 	}
 
+	profile := envProfile(prefix)
+
 	for _, format := range []string{"json", "toml", "yaml"} {
 		envar := strings.ToUpper(fmt.Sprintf("%sCONFIG_%s", prefix, format))
 		if envstr, ok := os.LookupEnv(envar); ok {
 			// format = "json"
-			err := Decode(strings.NewReader(envstr), format, &o)
-			return &o, err
+			err := decodeConfig([]byte(envstr), format, profile, &o)
+			if err != nil {
+				return &o, err
+			}
+
+			return &o, finishConfig(prefix, &o)
 		}
 	}
 
 	// read as file if {prefix}_CONFIG, using file extension to determine the format:
 	envar := fmt.Sprintf("%sCONFIG_FILE", prefix)
 	if configFile, ok := os.LookupEnv(envar); ok {
-		err := DecodeFile(configFile, &o)
-		return &o, err
+		err := decodeConfigFile(configFile, profile, &o)
+		if err != nil {
+			return &o, err
+		}
+
+		return &o, finishConfig(prefix, &o)
+	}
+
+	// fetch from {prefix}_CONFIG_URL, an http(s) URL or s3 URI
+	envar = fmt.Sprintf("%sCONFIG_URL", prefix)
+	if configURL, ok := os.LookupEnv(envar); ok {
+		err := decodeRemoteConfig(prefix, configURL, &o)
+		if err != nil {
+			return &o, err
+		}
+
+		return &o, finishConfig(prefix, &o)
+	}
+
+	// look for the config file in the standard locations
+	if appName != "" {
+		if configFile, ok := FindConfigFile(appName); ok {
+			err := decodeConfigFile(configFile, profile, &o)
+			if err != nil {
+				return &o, err
+			}
+
+			return &o, finishConfig(prefix, &o)
+		}
 	}
 
 	return nil, fmt.Errorf("%w: try setting %sCONFIG_FILE", ErrNoConfig, prefix)
 }
+
+// finishConfig applies the environment overrides to a decoded config, reads
+// the secret files, and validates it.
+func finishConfig(prefix string, o any) error {
+	err := overrideEnv(prefix, o)
+	if err != nil {
+		return err
+	}
+
+	return checkConfig(o)
+}
+
+// checkConfig reads the secret files of a decoded config, and validates it.
+func checkConfig(o any) error {
+	err := resolveSecrets(o)
+	if err != nil {
+		return err
+	}
+
+	return ValidateConfig(o)
+}
+
+// ConfigOption configures ParseConfigFile and ParseConfigReader.
+type ConfigOption func(*configOptions)
+
+type configOptions struct {
+	profile string
+}
+
+func newConfigOptions(opts []ConfigOption) configOptions {
+	var o configOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithProfile merges the named profile of the config over the defaults, as
+// {prefix}_ENV does for ParseConfig. An empty name selects no profile.
+func WithProfile(name string) ConfigOption {
+	return func(o *configOptions) {
+		o.profile = name
+	}
+}
+
+// ParseConfigFile decodes the config file, using the file extension to
+// determine the format, e.g. for a --config flag. Like ParseConfig, it merges
+// in the includes, migrates deprecated keys, reads the secret files and
+// validates the config, but there are no environment overrides. The profile
+// is selected with WithProfile:
+//
+//	cfg, err := goo.ParseConfigFile[AppConfig](args.Config, goo.WithProfile(args.Env))
+func ParseConfigFile[T any](path string, opts ...ConfigOption) (*T, error) {
+	var o T
+
+	err := decodeConfigFile(path, newConfigOptions(opts).profile, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkConfig(&o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+// ParseConfigReader decodes the config from the reader in the format, one of
+// "toml", "yaml", "json" or "jsonc". See ParseConfigFile.
+func ParseConfigReader[T any](r io.Reader, format string, opts ...ConfigOption) (*T, error) {
+	var o T
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	err = decodeConfig(data, format, newConfigOptions(opts).profile, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkConfig(&o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
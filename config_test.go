@@ -0,0 +1,245 @@
+package goo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfigEnvOverrides(t *testing.T) {
+	assert := assert.New(t)
+
+	type AppConfig struct {
+		Config
+
+		Timeout time.Duration
+		Hosts   []string
+		Token   string `env:"API_TOKEN"`
+		Secret  string `env:"-"`
+	}
+
+	t.Setenv("APP_CONFIG_JSON", `{"Database": {"Dialect": "sqlite3", "DSN": "dev.db"}, "Logging": {"LogLevel": "debug"}}`)
+	t.Setenv("APP_DATABASE_DSN", "prod.db")
+	t.Setenv("APP_LOGGING_LOGLEVEL", "warn")
+	t.Setenv("APP_DEBUG_LISTEN", "localhost:6060")
+	t.Setenv("APP_TIMEOUT", "5s")
+	t.Setenv("APP_HOSTS", "a.example.com, b.example.com")
+	t.Setenv("APP_API_TOKEN", "token")
+	t.Setenv("APP_SECRET", "secret")
+
+	cfg, err := ParseConfig[AppConfig]("app")
+	assert.NoError(err)
+
+	assert.Equal(&DatabaseConfig{Dialect: "sqlite3", DSN: "prod.db"}, cfg.Database)
+	assert.Equal("warn", cfg.Logging.LogLevel)
+	assert.Equal(&DebugConfig{Listen: "localhost:6060"}, cfg.Debug)
+	assert.Nil(cfg.Echo)
+	assert.Equal(5*time.Second, cfg.Timeout)
+	assert.Equal([]string{"a.example.com", "b.example.com"}, cfg.Hosts)
+	assert.Equal("token", cfg.Token)
+	assert.Empty(cfg.Secret)
+
+	t.Setenv("APP_TIMEOUT", "soon")
+	_, err = ParseConfig[AppConfig]("app")
+	assert.ErrorContains(err, "env APP_TIMEOUT")
+}
+
+func TestParseConfigEnvRecursiveType(t *testing.T) {
+	assert := assert.New(t)
+
+	type Node struct {
+		Name string
+		Next *Node
+	}
+
+	type AppConfig struct {
+		Root *Node
+	}
+
+	t.Setenv("APP_CONFIG_JSON", `{}`)
+	t.Setenv("APP_ROOT_NEXT_NAME", "second")
+
+	cfg, err := ParseConfig[AppConfig]("app")
+	assert.NoError(err)
+	assert.Equal(&Node{Next: &Node{Name: "second"}}, cfg.Root)
+}
+
+func TestParseConfigMap(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv("APP_CONFIG_JSON", `{"workers": 8}`)
+
+	cfg, err := ParseConfig[map[string]any]("app")
+	assert.NoError(err)
+	assert.Equal(map[string]any{"workers": float64(8)}, *cfg)
+}
+
+func TestParseConfigValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	type ServerConfig struct {
+		URL  string `validate:"required,url"`
+		Mode string `validate:"oneof=dev prod"`
+	}
+
+	type AppConfig struct {
+		Server  *ServerConfig `validate:"required"`
+		Workers int           `validate:"min=1,max=64"`
+	}
+
+	t.Setenv("APP_CONFIG_JSON", `{"Server": {"URL": "not a url", "Mode": "staging"}, "Workers": 100}`)
+
+	_, err := ParseConfig[AppConfig]("app")
+
+	var verr *ValidationError
+	assert.ErrorAs(err, &verr)
+	assert.Equal([]FieldError{
+		{Field: "Server.URL", Rule: "url", Value: "not a url"},
+		{Field: "Server.Mode", Rule: "oneof=dev prod", Value: "staging"},
+		{Field: "Workers", Rule: "max=64", Value: 100},
+	}, verr.Fields)
+	assert.Contains(err.Error(), "Server.URL: failed url (value: not a url)")
+
+	t.Setenv("APP_CONFIG_JSON", `{"Server": {"URL": "https://example.com", "Mode": "prod"}, "Workers": 8}`)
+
+	cfg, err := ParseConfig[AppConfig]("app")
+	assert.NoError(err)
+	assert.Equal(8, cfg.Workers)
+}
+
+func TestParseConfigSecretFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, "db_password"), []byte("s3cret\n"), 0o600))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "api_key"), []byte("key\n"), 0o600))
+
+	type AppConfig struct {
+		Config
+
+		Password string
+		Token    string
+		Socket   string `secret:"-"`
+	}
+
+	dsn := "file://" + filepath.Join(dir, "app.db")
+	t.Setenv("APP_CONFIG_JSON", `{"Password": "file://`+filepath.Join(dir, "db_password")+`", "Database": {"DSN": "`+dsn+`"}}`)
+	t.Setenv("APP_TOKEN", "file://"+filepath.Join(dir, "api_key"))
+	t.Setenv("APP_SOCKET", "file:///run/app.sock")
+
+	cfg, err := ParseConfig[AppConfig]("app")
+	assert.NoError(err)
+	assert.Equal("s3cret", cfg.Password)
+	assert.Equal("key", cfg.Token)
+	// fields tagged secret:"-" keep file:// URIs, e.g. sqlite DSNs
+	assert.Equal(dsn, cfg.Database.DSN)
+	assert.Equal("file:///run/app.sock", cfg.Socket)
+
+	t.Setenv("APP_TOKEN", "file://"+filepath.Join(dir, "missing"))
+	_, err = ParseConfig[AppConfig]("app")
+	assert.ErrorContains(err, "secret Token")
+}
+
+func TestSecretFileTag(t *testing.T) {
+	assert := assert.New(t)
+
+	wd, err := os.Getwd()
+	assert.NoError(err)
+	assert.NoError(os.Chdir(t.TempDir()))
+	defer os.Chdir(wd)
+
+	assert.NoError(os.WriteFile("api_key", []byte("key\n"), 0o600))
+
+	type SecretConfig struct {
+		APIKey  string `secret_file:"api_key"`
+		Inline  string `secret_file:"api_key"`
+		Missing string `secret_file:"missing"`
+	}
+
+	cfg := SecretConfig{Inline: "inline"}
+	assert.NoError(resolveSecrets(&cfg))
+	assert.Equal(SecretConfig{APIKey: "key", Inline: "inline"}, cfg)
+}
+
+func TestParseConfigFile(t *testing.T) {
+	assert := assert.New(t)
+
+	type AppConfig struct {
+		Name    string `validate:"required"`
+		Workers int
+	}
+
+	file := filepath.Join(t.TempDir(), "config.toml")
+	assert.NoError(os.WriteFile(file, []byte("Name = \"app\"\nWorkers = 4\n"), 0o644))
+
+	// no environment overrides
+	t.Setenv("WORKERS", "8")
+
+	cfg, err := ParseConfigFile[AppConfig](file)
+	assert.NoError(err)
+	assert.Equal(&AppConfig{Name: "app", Workers: 4}, cfg)
+
+	cfg, err = ParseConfigReader[AppConfig](strings.NewReader(`{"Name": "app", "Workers": 2}`), JSONFormat)
+	assert.NoError(err)
+	assert.Equal(&AppConfig{Name: "app", Workers: 2}, cfg)
+
+	_, err = ParseConfigReader[AppConfig](strings.NewReader(`{"Workers": 2}`), JSONFormat)
+	assert.ErrorContains(err, "Name: failed required")
+
+	// the shell's ENV isn't a profile
+	t.Setenv("ENV", "/root/.ashrc")
+	cfg, err = ParseConfigReader[AppConfig](strings.NewReader(`{"Name": "app", "profiles": {"prod": {"Workers": 16}}}`), JSONFormat)
+	assert.NoError(err)
+	assert.Equal(&AppConfig{Name: "app"}, cfg)
+
+	cfg, err = ParseConfigReader[AppConfig](strings.NewReader(`{"Name": "app", "profiles": {"prod": {"Workers": 16}}}`), JSONFormat, WithProfile("prod"))
+	assert.NoError(err)
+	assert.Equal(&AppConfig{Name: "app", Workers: 16}, cfg)
+
+	_, err = ParseConfigReader[AppConfig](strings.NewReader(`{"Name": "app", "profiles": {"prod": {}}}`), JSONFormat, WithProfile("staging"))
+	assert.ErrorContains(err, `config profile "staging" not found`)
+}
+
+type testArgs struct {
+	Workers int    `arg:"--workers" help:"number of workers"`
+	Name    string `arg:"positional,required"`
+}
+
+func (testArgs) Version() string {
+	return "app 1.0"
+}
+
+func TestParseArgsFrom(t *testing.T) {
+	assert := assert.New(t)
+
+	args, err := ParseArgsFrom[testArgs]([]string{"--workers", "4", "job"})
+	assert.NoError(err)
+	assert.Equal(&testArgs{Workers: 4, Name: "job"}, args)
+
+	for _, tc := range []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{[]string{"--help"}, 0, "number of workers", ""},
+		{[]string{"--version"}, 0, "app 1.0\n", ""},
+		{[]string{"--workers", "many", "job"}, 2, "", "error: error processing --workers"},
+		{[]string{}, 2, "", "error: name is required"},
+	} {
+		_, err := ParseArgsFrom[testArgs](tc.args)
+
+		var argsErr *ArgsError
+		assert.ErrorAs(err, &argsErr, tc.args)
+		assert.Equal(tc.code, ExitCode(err), tc.args)
+
+		var stdout, stderr strings.Builder
+		argsErr.print(&stdout, &stderr)
+		assert.Contains(stdout.String(), tc.stdout, tc.args)
+		assert.Contains(stderr.String(), tc.stderr, tc.args)
+	}
+}
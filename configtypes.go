@@ -0,0 +1,196 @@
+package goo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Duration is a time.Duration that config files and environment variables
+// spell as "30s" or "1h30m". JSON numbers are nanoseconds, as for
+// time.Duration.
+type Duration time.Duration
+
+// Std returns the time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+	return nil
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var v any
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case string:
+		return d.UnmarshalText([]byte(v))
+	case float64:
+		*d = Duration(v)
+		return nil
+	default:
+		return fmt.Errorf("invalid duration: %s", data)
+	}
+}
+
+// ByteSize is a number of bytes that config files and environment variables
+// spell as "512MB" or "1.5GiB". KB, MB, GB and TB are powers of 1000, and KiB,
+// MiB, GiB and TiB powers of 1024. JSON numbers are bytes.
+type ByteSize int64
+
+// Byte sizes.
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000 * Byte
+	MB ByteSize = 1000 * KB
+	GB ByteSize = 1000 * MB
+	TB ByteSize = 1000 * GB
+
+	KiB ByteSize = 1024 * Byte
+	MiB ByteSize = 1024 * KiB
+	GiB ByteSize = 1024 * MiB
+	TiB ByteSize = 1024 * GiB
+)
+
+// byteUnits are ordered from the largest, preferring binary units.
+var byteUnits = []struct {
+	name string
+	size ByteSize
+}{
+	{"TiB", TiB}, {"TB", TB},
+	{"GiB", GiB}, {"GB", GB},
+	{"MiB", MiB}, {"MB", MB},
+	{"KiB", KiB}, {"KB", KB},
+	{"B", Byte},
+}
+
+// ParseByteSize parses a size like "512MB", "1.5 GiB" or "100". Units are
+// case-insensitive.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsLetter(r)
+	})
+
+	num, unit := s, "B"
+	if i >= 0 {
+		num, unit = strings.TrimSpace(s[:i]), s[i:]
+	}
+
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid byte size: %q", s)
+	}
+
+	for _, u := range byteUnits {
+		if strings.EqualFold(u.name, unit) {
+			size := v * float64(u.size)
+			if size > math.MaxInt64 {
+				return 0, fmt.Errorf("byte size out of range: %q", s)
+			}
+
+			return ByteSize(size), nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid byte size unit: %q", s)
+}
+
+// String returns the size in the largest unit that divides it, e.g. "512MiB".
+func (b ByteSize) String() string {
+	for _, u := range byteUnits {
+		if b != 0 && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.name
+		}
+	}
+
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
+
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	v, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = v
+	return nil
+}
+
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	var v any
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case string:
+		return b.UnmarshalText([]byte(v))
+	case float64:
+		*b = ByteSize(v)
+		return nil
+	default:
+		return fmt.Errorf("invalid byte size: %s", data)
+	}
+}
+
+// URL is a url.URL that config files and environment variables spell as a
+// string. The zero URL is the empty string.
+type URL struct {
+	url.URL
+}
+
+// ParseURL parses the URL.
+func ParseURL(s string) (URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return URL{}, err
+	}
+
+	return URL{*u}, nil
+}
+
+func (u URL) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *URL) UnmarshalText(text []byte) error {
+	v, err := ParseURL(string(text))
+	if err != nil {
+		return err
+	}
+
+	*u = v
+	return nil
+}
@@ -0,0 +1,77 @@
+package goo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteSize(t *testing.T) {
+	assert := assert.New(t)
+
+	for s, want := range map[string]ByteSize{
+		"100":     100,
+		"512MB":   512 * MB,
+		"512mb":   512 * MB,
+		"1.5 GiB": 1536 * MiB,
+		"2KiB":    2048,
+	} {
+		size, err := ParseByteSize(s)
+		assert.NoError(err, s)
+		assert.Equal(want, size, s)
+	}
+
+	_, err := ParseByteSize("10 parsecs")
+	assert.Error(err)
+	_, err = ParseByteSize("-1KB")
+	assert.Error(err)
+
+	assert.Equal("512MiB", (512 * MiB).String())
+	assert.Equal("3MB", (3 * MB).String())
+	assert.Equal("1001B", ByteSize(1001).String())
+	assert.Equal("0B", ByteSize(0).String())
+}
+
+func TestConfigTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	type AppConfig struct {
+		Timeout   Duration `validate:"min=1s"`
+		MaxUpload ByteSize `validate:"max=1073741824"`
+		Upstream  URL      `validate:"required,url"`
+	}
+
+	for _, tc := range []struct{ format, config string }{
+		{JSONFormat, `{"Timeout": "30s", "MaxUpload": "512MB", "Upstream": "https://api.example.com/v1"}`},
+		{YAMLFormat, "Timeout: 30s\nMaxUpload: 512MB\nUpstream: https://api.example.com/v1\n"},
+		{TOMLFormat, "Timeout = \"30s\"\nMaxUpload = \"512MB\"\nUpstream = \"https://api.example.com/v1\"\n"},
+	} {
+		cfg, err := ParseConfigReader[AppConfig](strings.NewReader(tc.config), tc.format)
+		assert.NoError(err, tc.format)
+		assert.Equal(30*time.Second, cfg.Timeout.Std(), tc.format)
+		assert.Equal(512*MB, cfg.MaxUpload, tc.format)
+		assert.Equal("api.example.com", cfg.Upstream.Host, tc.format)
+	}
+
+	t.Setenv("APP_CONFIG_JSON", `{"Timeout": 1000000000, "MaxUpload": 1024, "Upstream": "https://api.example.com"}`)
+	t.Setenv("APP_TIMEOUT", "1m")
+	t.Setenv("APP_MAXUPLOAD", "1GiB")
+
+	cfg, err := ParseConfig[AppConfig]("app")
+	assert.NoError(err)
+	assert.Equal(time.Minute, cfg.Timeout.Std())
+	assert.Equal(GiB, cfg.MaxUpload)
+
+	var buf strings.Builder
+	assert.NoError(DumpConfig(cfg, &buf, JSONFormat))
+	assert.Contains(buf.String(), `"Timeout": "1m0s"`)
+	assert.Contains(buf.String(), `"MaxUpload": "1GiB"`)
+	assert.Contains(buf.String(), `"Upstream": "https://api.example.com"`)
+
+	_, err = ParseConfigReader[AppConfig](strings.NewReader(`{"Timeout": "10ms", "MaxUpload": "2GB", "Upstream": ""}`), JSONFormat)
+	var verr *ValidationError
+	assert.ErrorAs(err, &verr)
+	assert.Len(verr.Fields, 3)
+}
@@ -0,0 +1,196 @@
+package goo
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// configDebounce coalesces the burst of events of a single save.
+const configDebounce = 100 * time.Millisecond
+
+// ConfigWatcher reloads a config file when it changes, and on SIGHUP. A config
+// that fails to decode or validate is logged and ignored, so the current
+// config stays in effect.
+//
+//	watcher, err := goo.WatchConfig[AppConfig](ctx, "config.toml", "app")
+//	watcher.OnChange(func(cfg *AppConfig) {
+//		limiter.SetLimit(cfg.RateLimit)
+//	})
+type ConfigWatcher[T any] struct {
+	file   string
+	prefix string
+	ctx    *ShutdownContext
+
+	// reloadMu serializes the reloads of the watcher and SIGHUP, so that an
+	// older config doesn't replace a newer one.
+	reloadMu sync.Mutex
+
+	mu        sync.Mutex
+	config    *T
+	callbacks []func(cfg *T)
+}
+
+// WatchConfig loads the config file as ParseConfig does, with the environment
+// overrides of prefix, and watches it until the ShutdownContext is done.
+func WatchConfig[T any](ctx *ShutdownContext, file string, prefix string) (*ConfigWatcher[T], error) {
+	w := &ConfigWatcher[T]{file: filepath.Clean(file), prefix: prefix, ctx: ctx}
+
+	cfg, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	w.config = cfg
+
+	// watch the directory, as editors and Kubernetes replace the file rather
+	// than write to it
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("watch config: %w", err)
+	}
+
+	err = fsw.Add(filepath.Dir(w.file))
+	if err != nil {
+		fsw.Close()
+		return nil, fmt.Errorf("watch config: %w", err)
+	}
+
+	go w.watch(fsw)
+
+	ctx.OnReload(func(context.Context) error {
+		return w.Reload()
+	})
+
+	return w, nil
+}
+
+// Config returns the current config. Treat it as read-only: it's shared with
+// the other callers.
+func (w *ConfigWatcher[T]) Config() *T {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.config
+}
+
+// OnChange registers a function called with the new config after a reload.
+func (w *ConfigWatcher[T]) OnChange(fn func(cfg *T)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.callbacks = append(w.callbacks, fn)
+}
+
+// Subscribe returns a channel that receives the new config after a reload. A
+// slow receiver only gets the latest config.
+func (w *ConfigWatcher[T]) Subscribe() <-chan *T {
+	ch := make(chan *T, 1)
+
+	w.OnChange(func(cfg *T) {
+		for {
+			select {
+			case ch <- cfg:
+				return
+			default:
+			}
+
+			// drop the config the receiver hasn't taken yet
+			select {
+			case <-ch:
+			default:
+			}
+		}
+	})
+
+	return ch
+}
+
+// Reload loads the config file, and notifies the subscribers if it's valid.
+func (w *ConfigWatcher[T]) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	log := w.ctx.logger
+
+	cfg, err := w.load()
+	if err != nil {
+		log.Error("config reload failed", "file", w.file, "error", err.Error())
+		return err
+	}
+
+	w.mu.Lock()
+	w.config = cfg
+	callbacks := w.callbacks
+	w.mu.Unlock()
+
+	log.Info("config reloaded", "file", w.file)
+
+	for _, fn := range callbacks {
+		fn(cfg)
+	}
+
+	return nil
+}
+
+func (w *ConfigWatcher[T]) load() (*T, error) {
+	var o T
+
+	prefix := strings.ToUpper(w.prefix)
+	if prefix != "" {
+		prefix += "_"
+	}
+
+	err := decodeConfigFile(w.file, envProfile(prefix), &o)
+	if err != nil {
+		return nil, err
+	}
+
+	err = finishConfig(prefix, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+func (w *ConfigWatcher[T]) watch(fsw *fsnotify.Watcher) {
+	defer fsw.Close()
+
+	log := w.ctx.logger
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return
+			}
+			log.Error("config watch error", "file", w.file, "error", err.Error())
+		case ev, ok := <-fsw.Events:
+			if !ok {
+				return
+			}
+
+			// Kubernetes swaps the ..data symlink of ConfigMap volumes
+			if filepath.Clean(ev.Name) != w.file && filepath.Base(ev.Name) != "..data" {
+				continue
+			}
+
+			if ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Chmod) {
+				continue
+			}
+
+			debounce = time.After(configDebounce)
+		case <-debounce:
+			debounce = nil
+			w.Reload()
+		}
+	}
+}
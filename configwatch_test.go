@@ -0,0 +1,97 @@
+package goo
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	type AppConfig struct {
+		Workers int `validate:"min=1"`
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(os.WriteFile(file, []byte(`{"Workers": 2}`), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := &ShutdownContext{Context: ctx, logger: slog.Default()}
+
+	w, err := WatchConfig[AppConfig](c, file, "app")
+	assert.NoError(err)
+	assert.Equal(2, w.Config().Workers)
+
+	changes := w.Subscribe()
+
+	assert.NoError(os.WriteFile(file, []byte(`{"Workers": 4}`), 0o644))
+
+	select {
+	case cfg := <-changes:
+		assert.Equal(4, cfg.Workers)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no config change")
+	}
+
+	// invalid configs are ignored
+	assert.NoError(os.WriteFile(file, []byte(`{"Workers": 0}`), 0o644))
+	assert.Error(c.Reload())
+	assert.Equal(4, w.Config().Workers)
+
+	// env overrides apply to reloads
+	t.Setenv("APP_WORKERS", "8")
+	assert.NoError(c.Reload())
+	assert.Equal(8, w.Config().Workers)
+}
+
+func TestWatchConfigConcurrentReloads(t *testing.T) {
+	assert := assert.New(t)
+
+	type AppConfig struct {
+		Workers int
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(os.WriteFile(file, []byte(`{"Workers": 2}`), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := &ShutdownContext{Context: ctx, logger: slog.Default()}
+
+	w, err := WatchConfig[AppConfig](c, file, "app")
+	assert.NoError(err)
+
+	// a subscriber that doesn't receive doesn't block the reloads
+	changes := w.Subscribe()
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(w.Reload())
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reloads blocked")
+	}
+
+	assert.Equal(2, (<-changes).Workers)
+}
@@ -0,0 +1,137 @@
+package goo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// replicaHealthInterval is how often DBCluster pings the replicas.
+const replicaHealthInterval = 10 * time.Second
+
+// DBCluster splits reads and writes between the database of ProvideSQLX, the
+// writer, and its read replicas:
+//
+//	cluster.Writer().Exec("UPDATE users SET name = ? WHERE id = ?", name, id)
+//	cluster.Reader().Get(&user, "SELECT * FROM users WHERE id = ?", id)
+//
+// Readers are picked round-robin among the healthy replicas. The replicas are
+// pinged every 10 seconds, and the writer serves reads if none is healthy.
+type DBCluster struct {
+	writer   *sqlx.DB
+	replicas []*replica
+	next     atomic.Uint64
+	log      *slog.Logger
+}
+
+type replica struct {
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+// ProvideDBCluster opens the ReplicaDSNs as ProvideSQLX opens the DSN, and
+// checks their health until shutdown.
+func ProvideDBCluster(goocfg *Config, writer *sqlx.DB, down *ShutdownContext, log *slog.Logger) (*DBCluster, error) {
+	if goocfg.Database == nil {
+		return nil, fmt.Errorf("no database configuration")
+	}
+
+	cfg := goocfg.Database
+
+	c := &DBCluster{writer: writer, log: log.With("_type", "DBCluster")}
+
+	for _, dsn := range cfg.ReplicaDSNs {
+		db, err := openDB(cfg, dsn, c.log)
+		if err != nil {
+			c.close()
+			return nil, fmt.Errorf("open replica: %w", err)
+		}
+
+		r := &replica{db: db}
+		r.healthy.Store(true)
+		c.replicas = append(c.replicas, r)
+	}
+
+	down.OnExit(func() error {
+		return c.close()
+	})
+
+	if len(c.replicas) > 0 {
+		c.CheckHealth(down)
+		go c.checkHealthLoop(down)
+	}
+
+	return c, nil
+}
+
+// Writer returns the writer database.
+func (c *DBCluster) Writer() *sqlx.DB {
+	return c.writer
+}
+
+// Reader returns the next healthy replica, or the writer if there is none.
+func (c *DBCluster) Reader() *sqlx.DB {
+	n := len(c.replicas)
+	if n == 0 {
+		return c.writer
+	}
+
+	healthy := make([]*sqlx.DB, 0, n)
+	for _, r := range c.replicas {
+		if r.healthy.Load() {
+			healthy = append(healthy, r.db)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return c.writer
+	}
+
+	return healthy[(c.next.Add(1)-1)%uint64(len(healthy))]
+}
+
+// CheckHealth pings the replicas, and updates which are healthy.
+func (c *DBCluster) CheckHealth(ctx context.Context) {
+	for i, r := range c.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		err := r.db.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) != healthy {
+			if healthy {
+				c.log.Info("replica is healthy", "replica", i)
+			} else {
+				c.log.Warn("replica is unhealthy", "replica", i, "error", err.Error())
+			}
+		}
+	}
+}
+
+func (c *DBCluster) checkHealthLoop(ctx context.Context) {
+	ticker := time.NewTicker(replicaHealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.CheckHealth(ctx)
+		}
+	}
+}
+
+func (c *DBCluster) close() error {
+	var errs []error
+	for _, r := range c.replicas {
+		errs = append(errs, r.db.Close())
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,78 @@
+package goo
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBCluster(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	cfg := &Config{Database: &DatabaseConfig{
+		Dialect: "sqlite3",
+		DSN:     filepath.Join(dir, "writer.db"),
+		ReplicaDSNs: []string{
+			filepath.Join(dir, "replica1.db"),
+			filepath.Join(dir, "missing", "replica2.db"),
+			filepath.Join(dir, "replica3.db"),
+		},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &ShutdownContext{Context: ctx, logger: slog.Default()}
+	defer c.runExitFns()
+
+	writer, err := ProvideSQLX(cfg, c, slog.Default())
+	assert.NoError(err)
+
+	cluster, err := ProvideDBCluster(cfg, writer, c, slog.Default())
+	assert.NoError(err)
+
+	assert.Same(writer, cluster.Writer())
+
+	// the replica in the missing directory is skipped
+	reads := map[any]int{}
+	for range 6 {
+		reads[cluster.Reader()]++
+	}
+	assert.Equal(map[any]int{
+		cluster.replicas[0].db: 3,
+		cluster.replicas[2].db: 3,
+	}, reads)
+
+	// the writer serves reads without healthy replicas
+	for _, r := range cluster.replicas {
+		r.healthy.Store(false)
+	}
+	assert.Same(writer, cluster.Reader())
+
+	cluster.CheckHealth(context.Background())
+	assert.True(cluster.replicas[0].healthy.Load())
+	assert.False(cluster.replicas[1].healthy.Load())
+}
+
+func TestDBClusterWithoutReplicas(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := &Config{Database: &DatabaseConfig{
+		Dialect: "sqlite3",
+		DSN:     filepath.Join(t.TempDir(), "app.db"),
+	}}
+
+	c := &ShutdownContext{Context: context.Background(), logger: slog.Default()}
+	defer c.runExitFns()
+
+	writer, err := ProvideSQLX(cfg, c, slog.Default())
+	assert.NoError(err)
+
+	cluster, err := ProvideDBCluster(cfg, writer, c, slog.Default())
+	assert.NoError(err)
+	assert.Same(writer, cluster.Reader())
+}
@@ -0,0 +1,390 @@
+package goo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// queryHook observes the queries of a database opened with openHookedDB. It
+// is called before the query, and the returned done function after, with the
+// rows affected by an exec, or read by a query, and the error of the query.
+// rows is -1 if unknown. The done function of a query is called when its rows
+// are closed.
+//
+// done isn't called if the driver skips the query with driver.ErrSkip, as
+// database/sql then runs it again as a prepared statement. Hooks must not
+// hold resources, e.g. open spans, until done is called.
+type queryHook func(ctx context.Context, query string, args []driver.NamedValue) (context.Context, queryDone)
+
+// queryDone is called when a query is done.
+type queryDone func(rows int64, err error)
+
+// openHookedDB opens the database with the driver of the dialect wrapped, so
+// that its queries go through the hooks.
+func openHookedDB(dialect, dsn string, hooks ...queryHook) (*sql.DB, error) {
+	db, err := sql.Open(dialect, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// sql.Open doesn't connect, it's only to look up the driver
+	drv := db.Driver()
+	db.Close()
+
+	var connector driver.Connector
+	if dc, ok := drv.(driver.DriverContext); ok {
+		connector, err = dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		connector = dsnConnector{dsn: dsn, driver: drv}
+	}
+
+	return sql.OpenDB(&hookConnector{connector: connector, hook: chainQueryHooks(hooks)}), nil
+}
+
+// chainQueryHooks runs the hooks in order, and their done functions in
+// reverse order.
+func chainQueryHooks(hooks []queryHook) queryHook {
+	return func(ctx context.Context, query string, args []driver.NamedValue) (context.Context, queryDone) {
+		dones := make([]queryDone, len(hooks))
+		for i, hook := range hooks {
+			ctx, dones[i] = hook(ctx, query, args)
+		}
+
+		return ctx, func(rows int64, err error) {
+			for i := len(dones) - 1; i >= 0; i-- {
+				dones[i](rows, err)
+			}
+		}
+	}
+}
+
+// dsnConnector is the connector of drivers that don't implement
+// driver.DriverContext.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type hookConnector struct {
+	connector driver.Connector
+	hook      queryHook
+}
+
+func (c *hookConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hookConn{Conn: conn, hook: c.hook}, nil
+}
+
+func (c *hookConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// hookConn forwards the optional interfaces of the driver's connection, as
+// database/sql only sees those of the wrapper.
+type hookConn struct {
+	driver.Conn
+	hook queryHook
+}
+
+func (c *hookConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *hookConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &hookStmt{Stmt: stmt, conn: c, query: query}, nil
+}
+
+func (c *hookConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("sql: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support read-only transactions")
+	}
+
+	return c.Conn.Begin()
+}
+
+func (c *hookConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, done := c.hook(ctx, query, args)
+	res, err := e.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		done(rowsAffected(res, err), err)
+	}
+
+	return res, err
+}
+
+func (c *hookConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, done := c.hook(ctx, query, args)
+	rows, err := q.QueryContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		return nil, err
+	}
+
+	return hookRowsOf(rows, err, done)
+}
+
+func (c *hookConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *hookConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *hookConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+func (c *hookConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+type hookStmt struct {
+	driver.Stmt
+	conn  *hookConn
+	query string
+}
+
+func (s *hookStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, done := s.conn.hook(ctx, s.query, args)
+	res, err := s.exec(ctx, args)
+	done(rowsAffected(res, err), err)
+
+	return res, err
+}
+
+func (s *hookStmt) exec(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return e.ExecContext(ctx, args)
+	}
+
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.Stmt.Exec(values)
+}
+
+func (s *hookStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, done := s.conn.hook(ctx, s.query, args)
+	rows, err := s.queryRows(ctx, args)
+
+	return hookRowsOf(rows, err, done)
+}
+
+func (s *hookStmt) queryRows(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return q.QueryContext(ctx, args)
+	}
+
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.Stmt.Query(values)
+}
+
+// CheckNamedValue checks with the statement, or else the connection, as
+// database/sql doesn't consult the connection if the statement is a checker.
+func (s *hookStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+
+	return s.conn.CheckNamedValue(nv)
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		values[i] = arg.Value
+	}
+
+	return values, nil
+}
+
+func rowsAffected(res driver.Result, err error) int64 {
+	if err != nil {
+		return -1
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+// hookRowsOf wraps the rows of a query, to count them until they're closed.
+func hookRowsOf(rows driver.Rows, err error, done queryDone) (driver.Rows, error) {
+	if err != nil {
+		done(-1, err)
+		return nil, err
+	}
+
+	return &hookRows{Rows: rows, done: done}, nil
+}
+
+// hookRows forwards the optional interfaces of the driver's rows, with the
+// defaults of database/sql.
+type hookRows struct {
+	driver.Rows
+	done queryDone
+
+	n   int64
+	err error
+}
+
+func (r *hookRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		r.n++
+	} else if err != io.EOF {
+		r.err = err
+	}
+
+	return err
+}
+
+func (r *hookRows) Close() error {
+	err := r.Rows.Close()
+	if r.done != nil {
+		r.done(r.n, r.err)
+		r.done = nil
+	}
+
+	return err
+}
+
+func (r *hookRows) HasNextResultSet() bool {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rs.HasNextResultSet()
+	}
+
+	return false
+}
+
+func (r *hookRows) NextResultSet() error {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rs.NextResultSet()
+	}
+
+	return io.EOF
+}
+
+func (r *hookRows) ColumnTypeScanType(index int) reflect.Type {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(index)
+	}
+
+	return reflect.TypeFor[any]()
+}
+
+func (r *hookRows) ColumnTypeDatabaseTypeName(index int) string {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(index)
+	}
+
+	return ""
+}
+
+func (r *hookRows) ColumnTypeLength(index int) (int64, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(index)
+	}
+
+	return 0, false
+}
+
+func (r *hookRows) ColumnTypeNullable(index int) (bool, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(index)
+	}
+
+	return false, false
+}
+
+func (r *hookRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(index)
+	}
+
+	return 0, 0, false
+}
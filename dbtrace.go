@@ -0,0 +1,110 @@
+package goo
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTraceHook records a client span for every query, named after its
+// statement summary, e.g. "SELECT users". The span ends when the rows of a
+// query are closed, with the number of rows.
+//
+// The span is started when the query is done, with the start time of the
+// query, so that no span is left open for a query the driver skips.
+func queryTraceHook(tp trace.TracerProvider, dialect string) queryHook {
+	tracer := tp.Tracer("github.com/hayeah/goo")
+	system := dbSystem(dialect)
+
+	return func(ctx context.Context, query string, args []driver.NamedValue) (context.Context, queryDone) {
+		start := time.Now()
+
+		return ctx, func(rows int64, err error) {
+			summary := querySummary(query)
+
+			_, span := tracer.Start(ctx, summary,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithTimestamp(start),
+				trace.WithAttributes(
+					semconv.DBSystemKey.String(system),
+					semconv.DBOperationName(strings.SplitN(summary, " ", 2)[0]),
+					semconv.DBQueryText(query),
+				),
+			)
+
+			if rows >= 0 {
+				span.SetAttributes(attribute.Int64("db.rows", rows))
+			}
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			span.End()
+		}
+	}
+}
+
+// dbSystem is the db.system of the dialect.
+func dbSystem(dialect string) string {
+	switch dialect {
+	case "sqlite3":
+		return "sqlite"
+	case "postgres", "pgx":
+		return "postgresql"
+	default:
+		return dialect
+	}
+}
+
+// querySummary is the operation of the query and the table it's on, e.g.
+// "SELECT users" or "INSERT users", or only the operation if the table isn't
+// found.
+func querySummary(query string) string {
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return "query"
+	}
+
+	op := strings.ToUpper(words[0])
+
+	var after string
+	switch op {
+	case "SELECT", "DELETE":
+		after = "FROM"
+	case "INSERT", "REPLACE":
+		after = "INTO"
+	case "UPDATE":
+		return summaryWithTable(op, words[1:], 0)
+	default:
+		return op
+	}
+
+	for i, word := range words[1:] {
+		if strings.EqualFold(word, after) {
+			return summaryWithTable(op, words[1:], i+1)
+		}
+	}
+
+	return op
+}
+
+func summaryWithTable(op string, words []string, i int) string {
+	if i >= len(words) {
+		return op
+	}
+
+	table, _, _ := strings.Cut(words[i], "(")
+	table = strings.Trim(table, "`\"[];")
+	if table == "" {
+		return op
+	}
+
+	return op + " " + table
+}
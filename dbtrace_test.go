@@ -0,0 +1,120 @@
+package goo
+
+import (
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestQueryTrace(t *testing.T) {
+	assert := assert.New(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	global := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(global) })
+
+	cfg := &Config{Database: &DatabaseConfig{
+		Dialect: "sqlite3",
+		DSN:     filepath.Join(t.TempDir(), "app.db"),
+		Trace:   true,
+	}}
+
+	c := &ShutdownContext{Context: context.Background(), logger: slog.Default()}
+	defer c.runExitFns()
+
+	db, err := ProvideSQLX(cfg, c, slog.Default())
+	assert.NoError(err)
+
+	_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	assert.NoError(err)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "handler")
+	_, err = db.ExecContext(ctx, "INSERT INTO users (name) VALUES (?), (?)", "alice", "bob")
+	assert.NoError(err)
+
+	var names []string
+	assert.NoError(db.SelectContext(ctx, &names, "SELECT name FROM users ORDER BY id"))
+	assert.Equal([]string{"alice", "bob"}, names)
+
+	_, err = db.ExecContext(ctx, "UPDATE missing SET name = ?", "carol")
+	assert.Error(err)
+	parent.End()
+
+	spans := recorder.Ended()
+	if !assert.Len(spans, 5) {
+		return
+	}
+
+	assert.Equal("CREATE", spans[0].Name())
+
+	insert, query, update := spans[1], spans[2], spans[3]
+	assert.Equal("INSERT users", insert.Name())
+	assert.Equal(trace.SpanKindClient, insert.SpanKind())
+	assert.Equal(parent.SpanContext().SpanID(), insert.Parent().SpanID())
+	assert.Contains(insert.Attributes(), attribute.String("db.system", "sqlite"))
+	assert.Contains(insert.Attributes(), attribute.String("db.operation.name", "INSERT"))
+	assert.Contains(insert.Attributes(), attribute.Int64("db.rows", 2))
+
+	assert.Equal("SELECT users", query.Name())
+	assert.Contains(query.Attributes(), attribute.String("db.query.text", "SELECT name FROM users ORDER BY id"))
+	assert.Contains(query.Attributes(), attribute.Int64("db.rows", 2))
+
+	assert.Equal("UPDATE missing", update.Name())
+	assert.Equal(codes.Error, update.Status().Code)
+}
+
+// skipConn skips every query, as drivers that only support prepared
+// statements do.
+type skipConn struct {
+	driver.Conn
+}
+
+func (skipConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (skipConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+func TestQueryTraceSkip(t *testing.T) {
+	assert := assert.New(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	conn := &hookConn{Conn: skipConn{}, hook: queryTraceHook(tp, "mysql")}
+
+	_, err := conn.ExecContext(context.Background(), "INSERT INTO users (name) VALUES (?)", nil)
+	assert.ErrorIs(err, driver.ErrSkip)
+	_, err = conn.QueryContext(context.Background(), "SELECT name FROM users", nil)
+	assert.ErrorIs(err, driver.ErrSkip)
+
+	// no span is left open
+	assert.Empty(recorder.Started())
+}
+
+func TestQuerySummary(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("SELECT users", querySummary("select id, name\n  from users where id = ?"))
+	assert.Equal("INSERT users", querySummary(`INSERT INTO "users"(name) VALUES (?)`))
+	assert.Equal("UPDATE users", querySummary("UPDATE users SET name = ?"))
+	assert.Equal("DELETE users", querySummary("DELETE FROM users WHERE id = ?"))
+	assert.Equal("SELECT", querySummary("SELECT 1"))
+	assert.Equal("PRAGMA", querySummary("PRAGMA journal_mode"))
+	assert.Equal("query", querySummary(""))
+}
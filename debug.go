@@ -0,0 +1,93 @@
+package goo
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	rpprof "runtime/pprof"
+)
+
+type DebugConfig struct {
+	// Listen is the address of the debug server, e.g. localhost:6060. Keep it
+	// private: the endpoints expose the internals of the process.
+	Listen string
+}
+
+// DebugServer serves pprof, expvar and goroutine dumps on a separate port:
+//
+//	/debug/pprof/      pprof profiles
+//	/debug/vars        expvar variables
+//	/debug/goroutines  stacks of all goroutines
+type DebugServer struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// ProvideDebugServer starts the debug server if configured, and shuts it down
+// on exit.
+func ProvideDebugServer(cfg *Config, ctx *ShutdownContext, log *slog.Logger) (*DebugServer, error) {
+	if cfg.Debug == nil || cfg.Debug.Listen == "" {
+		return &DebugServer{}, nil
+	}
+
+	log = log.With("_type", "DebugServer")
+
+	listener, err := net.Listen("tcp", cfg.Debug.Listen)
+	if err != nil {
+		return nil, fmt.Errorf("debug server: %w", err)
+	}
+
+	s := &DebugServer{
+		server:   &http.Server{Handler: DebugHandler()},
+		listener: listener,
+	}
+
+	go func() {
+		err := s.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("debug server", "error", err.Error())
+		}
+	}()
+
+	log.Debug("debug server started", "addr", s.Addr())
+
+	ctx.OnExitContextWithPriority(ExitPhaseDrain, func(ctx context.Context) error {
+		return s.server.Shutdown(ctx)
+	})
+
+	return s, nil
+}
+
+// Addr returns the address the server listens on, or "" if disabled.
+func (s *DebugServer) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+
+	return s.listener.Addr().String()
+}
+
+// DebugHandler returns the handler of the debug endpoints.
+func DebugHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	mux.HandleFunc("/debug/goroutines", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rpprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+
+	return mux
+}
@@ -0,0 +1,44 @@
+package goo
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugServer(t *testing.T) {
+	assert := assert.New(t)
+
+	shutdown := NewLifecycle(slog.Default()).ShutdownContext
+
+	s, err := ProvideDebugServer(&Config{Debug: &DebugConfig{Listen: "127.0.0.1:0"}}, shutdown, slog.Default())
+	assert.NoError(err)
+
+	res, err := http.Get("http://" + s.Addr() + "/debug/vars")
+	assert.NoError(err)
+	res.Body.Close()
+	assert.Equal(http.StatusOK, res.StatusCode)
+
+	code, body := serveGet(s.server.Handler, "/debug/pprof/")
+	assert.Equal(http.StatusOK, code)
+	assert.Contains(body, "goroutine")
+
+	code, body = serveGet(s.server.Handler, "/debug/vars")
+	assert.Equal(http.StatusOK, code)
+	assert.Contains(body, `"memstats"`)
+
+	code, body = serveGet(s.server.Handler, "/debug/goroutines")
+	assert.Equal(http.StatusOK, code)
+	assert.Contains(body, "TestDebugServer")
+
+	// shut down on exit
+	shutdown.runExitFns()
+	_, err = http.Get("http://" + s.Addr() + "/debug/vars")
+	assert.Error(err)
+
+	disabled, err := ProvideDebugServer(&Config{}, shutdown, slog.Default())
+	assert.NoError(err)
+	assert.Equal("", disabled.Addr())
+}
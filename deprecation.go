@@ -0,0 +1,105 @@
+package goo
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// ConfigDeprecation declares a renamed or removed config key.
+type ConfigDeprecation struct {
+	// Old is the dotted path of the deprecated key, e.g. "Logging.Level".
+	Old string
+	// New is the path the value moved to, e.g. "Logging.LogLevel". Empty if
+	// the key was removed.
+	New string
+}
+
+// ConfigDeprecator is implemented by configs with deprecated keys. ParseConfig
+// moves the values of renamed keys to their new path, drops removed keys, and
+// logs a warning for each:
+//
+//	func (AppConfig) ConfigDeprecations() []goo.ConfigDeprecation {
+//		return []goo.ConfigDeprecation{
+//			{Old: "Logging.Level", New: "Logging.LogLevel"},
+//			{Old: "Legacy"},
+//		}
+//	}
+type ConfigDeprecator interface {
+	ConfigDeprecations() []ConfigDeprecation
+}
+
+func configDeprecations(o any) []ConfigDeprecation {
+	if d, ok := o.(ConfigDeprecator); ok {
+		return d.ConfigDeprecations()
+	}
+
+	return nil
+}
+
+// migrateConfig applies the deprecations to the config map. A value set at
+// both the old and new path keeps the new value.
+func migrateConfig(m map[string]any, deprecations []ConfigDeprecation) {
+	for _, d := range deprecations {
+		v, ok := removeConfigPath(m, strings.Split(d.Old, "."))
+		if !ok {
+			continue
+		}
+
+		if d.New == "" {
+			slog.Warn("removed config key is ignored", "key", d.Old)
+			continue
+		}
+
+		slog.Warn("deprecated config key", "key", d.Old, "use", d.New)
+		setConfigPath(m, strings.Split(d.New, "."), v)
+	}
+}
+
+// removeConfigPath removes and returns the value at the path.
+func removeConfigPath(m map[string]any, path []string) (any, bool) {
+	key, ok := lookupKey(m, path[0])
+	if !ok {
+		return nil, false
+	}
+
+	if len(path) == 1 {
+		v := m[key]
+		delete(m, key)
+		return v, true
+	}
+
+	child, ok := m[key].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	return removeConfigPath(child, path[1:])
+}
+
+// setConfigPath sets the value at the path, unless it's already set.
+func setConfigPath(m map[string]any, path []string, v any) {
+	key, ok := lookupKey(m, path[0])
+	if !ok {
+		key = path[0]
+	}
+
+	if len(path) == 1 {
+		if !ok {
+			m[key] = v
+		}
+		return
+	}
+
+	child, isMap := m[key].(map[string]any)
+	if !isMap {
+		if ok {
+			// a value already set at the path
+			return
+		}
+
+		child = map[string]any{}
+		m[key] = child
+	}
+
+	setConfigPath(child, path[1:], v)
+}
@@ -0,0 +1,70 @@
+package goo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type deprecatedConfig struct {
+	Config
+
+	Workers int
+}
+
+func (deprecatedConfig) ConfigDeprecations() []ConfigDeprecation {
+	return []ConfigDeprecation{
+		{Old: "Logging.Level", New: "Logging.LogLevel"},
+		{Old: "Concurrency", New: "Workers"},
+		{Old: "DB", New: "Database"},
+		{Old: "Legacy"},
+	}
+}
+
+func TestConfigDeprecations(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv("APP_CONFIG_YAML", `
+logging:
+  level: debug
+concurrency: 4
+db:
+  dsn: app.db
+legacy: true
+profiles:
+  prod:
+    concurrency: 16
+`)
+
+	cfg, err := ParseConfig[deprecatedConfig]("app")
+	assert.NoError(err)
+	assert.Equal("debug", cfg.Logging.LogLevel)
+	assert.Equal(4, cfg.Workers)
+	assert.Equal("app.db", cfg.Database.DSN)
+
+	// profiles are migrated before merging
+	t.Setenv("APP_ENV", "prod")
+	cfg, err = ParseConfig[deprecatedConfig]("app")
+	assert.NoError(err)
+	assert.Equal(16, cfg.Workers)
+
+	// the new key wins
+	t.Setenv("APP_ENV", "")
+	t.Setenv("APP_CONFIG_JSON", `{"Concurrency": 4, "Workers": 8}`)
+	cfg, err = ParseConfig[deprecatedConfig]("app")
+	assert.NoError(err)
+	assert.Equal(8, cfg.Workers)
+}
+
+func TestParseConfigFileDeprecations(t *testing.T) {
+	assert := assert.New(t)
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(os.WriteFile(file, []byte("concurrency: 4\n"), 0o644))
+
+	cfg, err := ParseConfigFile[deprecatedConfig](file)
+	assert.NoError(err)
+	assert.Equal(4, cfg.Workers)
+}
@@ -4,9 +4,22 @@ import "github.com/google/wire"
 
 var Wires = wire.NewSet(
 	ProvideShutdownContext,
+	ProvideRunnerGroup,
+	ProvideScheduler,
+	ProvideSystemd,
+	ProvidePIDFile,
+	ProvideDebugServer,
 	ProvideSlog,
 	ProvideEcho,
+	ProvideHealth,
+	ProvideMetrics,
+	ProvideTracer,
 	ProvideSQLX,
+	ProvideDBCluster,
+	ProvideIDGenerator,
+	ProvideQueue,
+	ProvideKV,
+	ProvidePGListener,
 	ProvideMigrate,
 	ProvideEmbbededMigrate,
 )
@@ -0,0 +1,183 @@
+package goo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// LoadDotEnv sets environment variables from .env files, so that local
+// development goes through the same env-driven config as deployments. Call it
+// before ParseConfig or ParseArgs:
+//
+//	goo.LoadDotEnv(".env.local", ".env")
+//
+// Variables already in the environment are kept, and earlier files take
+// precedence over later ones. Missing files are skipped. Without paths, it
+// loads .env.
+//
+// A file has one KEY=value per line, optionally prefixed by "export". Values
+// may be single quoted (literal), double quoted (with \n, \t, \" and \\
+// escapes, and spanning lines), or unquoted with a trailing # comment.
+func LoadDotEnv(paths ...string) error {
+	return loadDotEnv(paths, false)
+}
+
+// LoadDotEnvOverride is LoadDotEnv, but the files override variables already
+// in the environment, and later files override earlier ones, e.g. for a
+// .env.local that takes precedence over the shell:
+//
+//	goo.LoadDotEnvOverride(".env", ".env.local")
+func LoadDotEnvOverride(paths ...string) error {
+	return loadDotEnv(paths, true)
+}
+
+func loadDotEnv(paths []string, override bool) error {
+	if len(paths) == 0 {
+		paths = []string{".env"}
+	}
+
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("dotenv: %w", err)
+		}
+
+		vars, err := parseDotEnv(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("dotenv %s: %w", path, err)
+		}
+
+		for _, v := range vars {
+			if _, ok := os.LookupEnv(v[0]); ok && !override {
+				continue
+			}
+
+			err := os.Setenv(v[0], v[1])
+			if err != nil {
+				return fmt.Errorf("dotenv %s: %w", path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseDotEnv returns the key value pairs of a .env file, in file order.
+func parseDotEnv(r io.Reader) ([][2]string, error) {
+	var vars [][2]string
+
+	scanner := bufio.NewScanner(r)
+	lineno := 0
+
+	next := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		lineno++
+		return scanner.Text(), true
+	}
+
+	for {
+		line, ok := next()
+		if !ok {
+			break
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineno)
+		}
+
+		key = strings.TrimSpace(key)
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: invalid key %q", lineno, key)
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated single quote", lineno)
+			}
+			value = value[1 : end+1]
+		case strings.HasPrefix(value, `"`):
+			start := lineno
+			raw := value[1:]
+
+			// read lines until the closing quote
+			for {
+				unquoted, ok := unquoteDotEnv(raw)
+				if ok {
+					value = unquoted
+					break
+				}
+
+				more, ok := next()
+				if !ok {
+					return nil, fmt.Errorf("line %d: unterminated double quote", start)
+				}
+				raw += "\n" + more
+			}
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+
+		vars = append(vars, [2]string{key, value})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// unquoteDotEnv unescapes s up to the closing double quote. It returns false
+// if there's no closing quote.
+func unquoteDotEnv(s string) (string, bool) {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			return b.String(), true
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,84 @@
+package goo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	vars, err := parseDotEnv(strings.NewReader(`
+# database
+APP_DATABASE_DSN=postgres://localhost/dev # local db
+export APP_LOGGING_LOGLEVEL = debug
+SINGLE='literal \n $HOME'
+DOUBLE="tab\tquote\" end"
+MULTI="-----BEGIN KEY-----
+abc
+-----END KEY-----"
+EMPTY=
+`))
+	assert.NoError(err)
+	assert.Equal([][2]string{
+		{"APP_DATABASE_DSN", "postgres://localhost/dev"},
+		{"APP_LOGGING_LOGLEVEL", "debug"},
+		{"SINGLE", `literal \n $HOME`},
+		{"DOUBLE", "tab\tquote\" end"},
+		{"MULTI", "-----BEGIN KEY-----\nabc\n-----END KEY-----"},
+		{"EMPTY", ""},
+	}, vars)
+
+	_, err = parseDotEnv(strings.NewReader("KEY=\"open\nnever closed\n"))
+	assert.EqualError(err, "line 1: unterminated double quote")
+
+	_, err = parseDotEnv(strings.NewReader("# ok\nNOVALUE\n"))
+	assert.EqualError(err, "line 2: expected KEY=value")
+}
+
+func TestLoadDotEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, ".env.local")
+	base := filepath.Join(dir, ".env")
+	assert.NoError(os.WriteFile(local, []byte("GOO_TEST_A=local\n"), 0o644))
+	assert.NoError(os.WriteFile(base, []byte("GOO_TEST_A=base\nGOO_TEST_B=base\nGOO_TEST_C=base\n"), 0o644))
+
+	t.Setenv("GOO_TEST_C", "process")
+	// t.Setenv restores the environment after the test
+	t.Setenv("GOO_TEST_A", "")
+	os.Unsetenv("GOO_TEST_A")
+	t.Setenv("GOO_TEST_B", "")
+	os.Unsetenv("GOO_TEST_B")
+
+	assert.NoError(LoadDotEnv(local, base, filepath.Join(dir, "missing")))
+
+	assert.Equal("local", os.Getenv("GOO_TEST_A"))
+	assert.Equal("base", os.Getenv("GOO_TEST_B"))
+	assert.Equal("process", os.Getenv("GOO_TEST_C"))
+}
+
+func TestLoadDotEnvOverride(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	assert.NoError(os.WriteFile(base, []byte("GOO_TEST_A=base\nGOO_TEST_B=base\n"), 0o644))
+	assert.NoError(os.WriteFile(local, []byte("GOO_TEST_A=local\n"), 0o644))
+
+	t.Setenv("GOO_TEST_A", "process")
+	t.Setenv("GOO_TEST_B", "process")
+	t.Setenv("GOO_TEST_C", "process")
+
+	assert.NoError(LoadDotEnvOverride(base, local))
+
+	assert.Equal("local", os.Getenv("GOO_TEST_A"))
+	assert.Equal("base", os.Getenv("GOO_TEST_B"))
+	assert.Equal("process", os.Getenv("GOO_TEST_C"))
+}
@@ -0,0 +1,101 @@
+package goo
+
+import (
+	"io"
+	"os"
+	"reflect"
+)
+
+// redactedValue replaces the string fields tagged `redact:"true"`.
+const redactedValue = "[REDACTED]"
+
+// DumpConfig writes the config in the format, one of "toml", "yaml" or
+// "json", with the fields tagged `redact:"true"` masked, e.g. to check which
+// config a service actually runs with:
+//
+//	type DatabaseConfig struct {
+//		DSN string `redact:"true"`
+//	}
+//
+// Redacted strings are replaced with "[REDACTED]", and other redacted fields
+// with their zero value. The config itself is not modified.
+func DumpConfig(cfg any, w io.Writer, format string) error {
+	return Encode(w, format, redactConfig(reflect.ValueOf(cfg)).Interface())
+}
+
+// redactConfig returns a copy of v with the redacted fields masked.
+func redactConfig(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(redactConfig(v.Elem()))
+		return p
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fv := c.Field(i)
+			if field.Tag.Get("redact") == "true" {
+				if fv.Kind() == reflect.String && fv.String() != "" {
+					fv.SetString(redactedValue)
+				} else {
+					fv.SetZero()
+				}
+				continue
+			}
+
+			fv.Set(redactConfig(fv))
+		}
+
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(redactConfig(v.Index(i)))
+		}
+		return s
+	}
+
+	return v
+}
+
+// ConfigCmd is a go-arg subcommand that prints the config with DumpConfig:
+//
+//	type Args struct {
+//		Config *goo.ConfigCmd `arg:"subcommand:config" help:"print the config"`
+//	}
+//
+//	func (a *App) Run(args *Args) error {
+//		if args.Config != nil {
+//			return args.Config.Run(a.cfg)
+//		}
+//		...
+//	}
+type ConfigCmd struct {
+	Format string `arg:"--format" default:"yaml" help:"output format: yaml, toml or json"`
+}
+
+// Run prints the config to stdout.
+func (c *ConfigCmd) Run(cfg any) error {
+	format := c.Format
+	if format == "" {
+		format = YAMLFormat
+	}
+
+	return DumpConfig(cfg, os.Stdout, format)
+}
@@ -0,0 +1,47 @@
+package goo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	type Upstream struct {
+		URL    string
+		APIKey string `redact:"true"`
+	}
+
+	type AppConfig struct {
+		Config
+
+		Upstreams []Upstream
+		Port      int `redact:"true"`
+	}
+
+	cfg := &AppConfig{
+		Config: Config{
+			Database: &DatabaseConfig{Dialect: "postgres", DSN: "postgres://user:pass@db/app"},
+		},
+		Upstreams: []Upstream{{URL: "https://a.example.com", APIKey: "key"}},
+		Port:      8080,
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(DumpConfig(cfg, &buf, JSONFormat))
+
+	out := buf.String()
+	assert.Contains(out, `"DSN": "[REDACTED]"`)
+	assert.Contains(out, `"APIKey": "[REDACTED]"`)
+	assert.Contains(out, `"URL": "https://a.example.com"`)
+	assert.Contains(out, `"Port": 0`)
+	assert.NotContains(out, "pass@db")
+
+	// the config is not modified
+	assert.Equal("postgres://user:pass@db/app", cfg.Database.DSN)
+	assert.Equal("key", cfg.Upstreams[0].APIKey)
+	assert.Equal(8080, cfg.Port)
+}
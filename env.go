@@ -0,0 +1,222 @@
+package goo
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// overrideEnv sets the fields of the struct pointed to by o from environment
+// variables named after the field path, e.g. APP_DATABASE_DSN for
+// Database.DSN with the "APP_" prefix. The `env` tag renames a field, and
+// `env:"-"` skips it. Fields of embedded structs are not prefixed. Nil struct
+// pointers are allocated if one of their fields is set.
+//
+// Configs that aren't structs, e.g. map[string]any, have no overrides.
+func overrideEnv(prefix string, o any) error {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	e := &envOverrider{visiting: map[envPath]bool{}}
+	_, err := e.overrideStruct(strings.TrimSuffix(prefix, "_"), v.Elem())
+	return err
+}
+
+// envPath is a struct type at an environment variable prefix.
+type envPath struct {
+	t    reflect.Type
+	name string
+}
+
+// envOverrider guards against recursive types, e.g. struct{ Next *Node }.
+// Nil pointers are only followed if a variable has their prefix, and a type
+// embedding itself is followed once.
+type envOverrider struct {
+	visiting map[envPath]bool
+}
+
+func (e *envOverrider) overrideStruct(prefix string, v reflect.Value) (bool, error) {
+	key := envPath{v.Type(), prefix}
+	if e.visiting[key] {
+		return false, nil
+	}
+
+	e.visiting[key] = true
+	defer delete(e.visiting, key)
+
+	var set bool
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, hasTag := field.Tag.Lookup("env")
+		if tag == "-" {
+			continue
+		}
+
+		var name string
+		switch {
+		case hasTag:
+			name = tag
+		case field.Anonymous:
+			// embedded structs share the prefix of their parent
+		default:
+			name = strings.ToUpper(field.Name)
+		}
+
+		switch {
+		case name == "":
+			name = prefix
+		case prefix != "":
+			name = prefix + "_" + name
+		}
+
+		ok, err := e.overrideValue(name, v.Field(i))
+		if err != nil {
+			return false, err
+		}
+		set = set || ok
+	}
+
+	return set, nil
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+func (e *envOverrider) overrideValue(name string, v reflect.Value) (bool, error) {
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		s, ok := os.LookupEnv(name)
+		if !ok {
+			return false, nil
+		}
+
+		err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		if err != nil {
+			return false, fmt.Errorf("env %s: %w", name, err)
+		}
+
+		return true, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return e.overrideStruct(name, v)
+	case reflect.Pointer:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			break
+		}
+
+		if !v.IsNil() {
+			return e.overrideValue(name, v.Elem())
+		}
+
+		if name != "" && !hasEnvPrefix(name) {
+			return false, nil
+		}
+
+		// only allocate the struct if the environment sets one of its fields
+		elem := reflect.New(v.Type().Elem())
+		ok, err := e.overrideValue(name, elem.Elem())
+		if ok {
+			v.Set(elem)
+		}
+		return ok, err
+	}
+
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return false, nil
+	}
+
+	err := setEnvValue(v, s)
+	if err != nil {
+		return false, fmt.Errorf("env %s: %w", name, err)
+	}
+
+	return true, nil
+}
+
+// hasEnvPrefix reports whether a variable is named name, or starts with
+// name_.
+func hasEnvPrefix(name string) bool {
+	for _, kv := range os.Environ() {
+		k, _, _ := strings.Cut(kv, "=")
+		if k == name || strings.HasPrefix(k, name+"_") {
+			return true
+		}
+	}
+
+	return false
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// setEnvValue parses s into v. Slices are comma separated.
+func setEnvValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var parts []string
+		if s != "" {
+			parts = strings.Split(s, ",")
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			err := setEnvValue(slice.Index(i), strings.TrimSpace(part))
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package goo
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultGracePeriod is how long a child process has to exit after SIGTERM.
+const defaultGracePeriod = 10 * time.Second
+
+// Cmd is a child process that stops with the context it runs with, e.g. to
+// shell out to ffmpeg or git. Its output is logged line by line.
+//
+//	transcode := goo.Exec("ffmpeg", "-i", src, dst)
+//	err := transcode.Run(ctx)
+//
+// Run it with a RunnerGroup so that the process waits for the child to exit
+// before running the exit functions:
+//
+//	worker := goo.Exec("./worker")
+//	worker.Restart = &goo.RestartPolicy{MaxRestarts: 5}
+//	group.Go("worker", worker.Run)
+type Cmd struct {
+	Name string
+	Args []string
+
+	// Dir is the working directory. Defaults to the current directory.
+	Dir string
+	// Env is the environment. Defaults to the environment of the process.
+	Env []string
+
+	// GracePeriod is how long the child has to exit after SIGTERM before it's
+	// killed. Defaults to 10 seconds.
+	GracePeriod time.Duration
+
+	// Restart restarts the child when it fails. nil runs it once.
+	Restart *RestartPolicy
+
+	// Logger logs stdout at info level, and stderr at warn level. Defaults to
+	// slog.Default.
+	Logger *slog.Logger
+}
+
+// Exec creates a Cmd that runs the named program with the arguments.
+func Exec(name string, args ...string) *Cmd {
+	return &Cmd{Name: name, Args: args}
+}
+
+// Run runs the child until it exits. When ctx is done, the child gets SIGTERM,
+// and SIGKILL if it's still running after the GracePeriod. With a Restart
+// policy, Run restarts the child as Supervise does.
+func (c *Cmd) Run(ctx context.Context) error {
+	if c.Restart != nil {
+		return Supervise(c.Name, c.run, *c.Restart, c.logger())(ctx)
+	}
+
+	return c.run(ctx)
+}
+
+func (c *Cmd) logger() *slog.Logger {
+	log := c.Logger
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return log
+}
+
+func (c *Cmd) run(ctx context.Context) error {
+	log := c.logger().With("_type", "Cmd", "name", c.Name)
+
+	grace := c.GracePeriod
+	if grace <= 0 {
+		grace = defaultGracePeriod
+	}
+
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Dir = c.Dir
+	cmd.Env = c.Env
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = grace
+
+	stdout := &logWriter{log: log, level: slog.LevelInfo, stream: "stdout"}
+	stderr := &logWriter{log: log, level: slog.LevelWarn, stream: "stderr"}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	log.Debug("starting", "args", c.Args)
+
+	err := cmd.Run()
+
+	stdout.Flush()
+	stderr.Flush()
+
+	if err != nil {
+		log.Debug("exited", "error", err.Error())
+		return err
+	}
+
+	log.Debug("exited")
+	return nil
+}
+
+// logWriter logs the lines written to it.
+type logWriter struct {
+	log    *slog.Logger
+	level  slog.Level
+	stream string
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.logLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush logs the last line if it has no newline.
+func (w *logWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.logLine(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *logWriter) logLine(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	w.log.Log(context.Background(), w.level, w.stream, "line", string(line))
+}
@@ -0,0 +1,59 @@
+package goo
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExec(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("logs output", func(t *testing.T) {
+		var buf bytes.Buffer
+		cmd := Exec("sh", "-c", "echo hello; echo oops >&2; printf partial")
+		cmd.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+
+		assert.NoError(cmd.Run(context.Background()))
+
+		out := buf.String()
+		assert.Contains(out, "level=INFO msg=stdout _type=Cmd name=sh line=hello")
+		assert.Contains(out, "level=WARN msg=stderr _type=Cmd name=sh line=oops")
+		assert.Contains(out, "line=partial")
+	})
+
+	t.Run("terminates", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := Exec("sleep", "10").Run(ctx)
+		assert.Error(err)
+		assert.Less(time.Since(start), 5*time.Second)
+	})
+
+	t.Run("kills after grace period", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		cmd := Exec("sh", "-c", `trap "" TERM; sleep 10 & wait; sleep 10`)
+		cmd.GracePeriod = 100 * time.Millisecond
+
+		start := time.Now()
+		err := cmd.Run(ctx)
+		assert.Error(err)
+		assert.Less(time.Since(start), 5*time.Second)
+	})
+
+	t.Run("restarts", func(t *testing.T) {
+		cmd := Exec("sh", "-c", "exit 3")
+		cmd.Restart = &RestartPolicy{MaxRestarts: 2, MinBackoff: time.Millisecond}
+
+		err := cmd.Run(context.Background())
+		assert.EqualError(err, "sh: gave up after 2 restarts: exit status 3")
+	})
+}
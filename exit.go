@@ -3,11 +3,13 @@ package goo
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
-	"os/signal"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 )
 
@@ -21,29 +23,128 @@ func GracefulExit() {
 	exitCtx.doExit()
 }
 
+// defaultExitTimeout is the default time budget of the exit functions.
+const defaultExitTimeout = 30 * time.Second
+
 type ShutdownContext struct {
 	context.Context
 
-	exitFns [](func() error)
+	// ExitTimeout is the deadline of the context passed to the exit functions
+	// registered with OnExitContext. Defaults to 30 seconds.
+	ExitTimeout time.Duration
+
+	exitFns []exitFn
 
 	mu        sync.Mutex
 	wg        sync.WaitGroup
 	waitCount int64
 	logger    *slog.Logger
+
+	signal atomic.Value // os.Signal
+
+	cancel   context.CancelCauseFunc
+	exit     func(code int)
+	exitOnce sync.Once
+
+	reloadMu  sync.Mutex
+	reloadFns []func(ctx context.Context) error
+	// onReload is called when a reload function is registered, for the
+	// Lifecycle to trap SIGHUP.
+	onReload func()
+
+	startFns []func(ctx context.Context) error
+	started  bool
+}
+
+// Signal returns the signal that triggered the shutdown, or nil if the process
+// is not shutting down or exits by GracefulExit.
+func (c *ShutdownContext) Signal() os.Signal {
+	sig, _ := c.signal.Load().(os.Signal)
+	return sig
+}
+
+// Shutdown starts a graceful shutdown, as if a signal was received.
+func (c *ShutdownContext) Shutdown() {
+	c.ShutdownWithError(nil)
+}
+
+// ShutdownWithError starts a graceful shutdown because of err. The process
+// exits with the ExitCode of err, and context.Cause returns err.
+func (c *ShutdownContext) ShutdownWithError(err error) {
+	if c.cancel != nil {
+		c.cancel(err)
+	}
+}
+
+// exitCode is the ExitCode of the error that caused the shutdown, or 0.
+func (c *ShutdownContext) exitCode() int {
+	cause := context.Cause(c)
+	if errors.Is(cause, context.Canceled) {
+		return 0
+	}
+
+	return ExitCode(cause)
+}
+
+// ExitError is an error with the exit code of the process, e.g. 2 for usage
+// errors.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for err: 0 for nil, the code of a wrapped
+// ExitError or ArgsError, or 1.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	var argsErr *ArgsError
+	if errors.As(err, &argsErr) {
+		return argsErr.ExitCode()
+	}
+
+	return 1
 }
 
 func (c *ShutdownContext) doExit() {
-	// may be called via GracefulExit or sigint. Lock this so there is only one
-	// caller, and blocking everyone until exit.
-	c.mu.Lock()
+	// may be called via GracefulExit or sigint. Only the first caller exits,
+	// and everyone else blocks until exit.
+	c.exitOnce.Do(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
 
-	// wait for blocking code
-	c.waitBlocks()
+		// wait for blocking code
+		c.waitBlocks()
 
-	// run exit cleanups
-	c.runExitFns()
+		// run exit cleanups
+		c.runExitFns()
 
-	os.Exit(0)
+		exit := c.exit
+		if exit == nil {
+			exit = os.Exit
+		}
+
+		exit(c.exitCode())
+	})
 }
 
 func (c *ShutdownContext) waitBlocks() {
@@ -74,8 +175,23 @@ func (c *ShutdownContext) runExitFns() {
 		log.Debug("running exit functions", "count", len(c.exitFns))
 	}
 
+	// stable, so functions of the same priority run in registration order
+	sort.SliceStable(c.exitFns, func(i, j int) bool {
+		return c.exitFns[i].priority < c.exitFns[j].priority
+	})
+
+	timeout := c.ExitTimeout
+	if timeout <= 0 {
+		timeout = defaultExitTimeout
+	}
+
+	// the shutdown context is already cancelled, so the deadline starts from
+	// a fresh context
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
 	for _, fn := range c.exitFns {
-		err := fn()
+		err := fn.fn(ctx)
 
 		if err != nil {
 			log.Debug("exit function error", "error", err.Error())
@@ -111,53 +227,148 @@ func (c *ShutdownContext) BlockExit(fn func() error) error {
 	return err
 }
 
+// Exit phases order the exit functions. Functions with a lower priority run
+// first, and functions of the same priority run in registration order.
+const (
+	// ExitPhaseDrain stops taking new work, e.g. shutting down HTTP servers.
+	ExitPhaseDrain = 100
+	// ExitPhaseFlush flushes buffered work, e.g. logs and metrics.
+	ExitPhaseFlush = 200
+	// ExitPhaseDefault is the priority of OnExit.
+	ExitPhaseDefault = 300
+	// ExitPhaseClose closes shared resources, e.g. database pools.
+	ExitPhaseClose = 400
+)
+
+type exitFn struct {
+	priority int
+	fn       func(ctx context.Context) error
+}
+
+// OnExit registers a function to run on exit, with ExitPhaseDefault priority.
 func (c *ShutdownContext) OnExit(fn func() error) {
+	c.OnExitWithPriority(ExitPhaseDefault, fn)
+}
+
+// OnExitWithPriority registers a function to run on exit. Functions with a
+// lower priority run first, e.g. ExitPhaseDrain before ExitPhaseClose.
+func (c *ShutdownContext) OnExitWithPriority(priority int, fn func() error) {
+	c.OnExitContextWithPriority(priority, func(context.Context) error {
+		return fn()
+	})
+}
+
+// OnExitContext registers a function to run on exit, with ExitPhaseDefault
+// priority. ctx is done when the ExitTimeout budget of all the exit functions
+// runs out.
+func (c *ShutdownContext) OnExitContext(fn func(ctx context.Context) error) {
+	c.OnExitContextWithPriority(ExitPhaseDefault, fn)
+}
+
+// OnExitContextWithPriority is OnExitContext with a priority. See
+// OnExitWithPriority.
+func (c *ShutdownContext) OnExitContextWithPriority(priority int, fn func(ctx context.Context) error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.exitFns = append(c.exitFns, fn)
+	c.exitFns = append(c.exitFns, exitFn{priority: priority, fn: fn})
 }
 
-var exitCtx *ShutdownContext
-var exitCtxOnce sync.Once
+// OnStart registers a function to run by Boot, after all the providers
+// succeeded, e.g. to warm caches or run migrations.
+func (c *ShutdownContext) OnStart(fn func(ctx context.Context) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-func ProvideShutdownContext(log *slog.Logger) (*ShutdownContext, error) {
-	// enforce that exitCtx is initialized once
-	exitCtxOnce.Do(func() {
-		bg := context.Background()
+	c.startFns = append(c.startFns, fn)
+}
+
+// Boot runs the start functions in registration order. It stops at the first
+// error, which should abort the boot. Boot only runs once; later calls do
+// nothing. Run calls Boot before running the runner.
+func (c *ShutdownContext) Boot() error {
+	c.mu.Lock()
+	if c.started {
+		c.mu.Unlock()
+		return nil
+	}
+	c.started = true
+	fns := c.startFns
+	c.mu.Unlock()
+
+	if len(fns) > 0 {
+		c.logger.Debug("running start functions", "count", len(fns))
+	}
+
+	for _, fn := range fns {
+		err := fn(c)
+		if err != nil {
+			return fmt.Errorf("start: %w", err)
+		}
+	}
 
-		sigs := make(chan os.Signal, 32)
-		signal.Notify(sigs, os.Interrupt)
+	return nil
+}
 
-		ctx, cancel := context.WithCancel(bg)
+// OnReload registers a function to run on SIGHUP, e.g. to re-read config,
+// reopen log files or refresh TLS certificates. Until a function is
+// registered, SIGHUP isn't trapped, and terminates the process as usual.
+func (c *ShutdownContext) OnReload(fn func(ctx context.Context) error) {
+	c.reloadMu.Lock()
+	c.reloadFns = append(c.reloadFns, fn)
+	c.reloadMu.Unlock()
 
-		exitCtx = &ShutdownContext{Context: ctx, logger: log}
+	if c.onReload != nil {
+		c.onReload()
+	}
+}
 
-		// 3 sigints to force an immediate exit
-		i := 0
-		go func() {
-			for {
-				<-sigs
+func (c *ShutdownContext) hasReloadFns() bool {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
 
-				if i == 0 {
-					cancel()
-				}
+	return len(c.reloadFns) > 0
+}
 
-				log.Debug("graceful exit. 3 sigints to exit immediately", "countdown", 3-i)
+// Reload runs the reload functions in registration order, and returns their
+// errors joined. Reloads are serialized: a reload waits for the one in
+// progress to finish.
+func (c *ShutdownContext) Reload() error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
 
-				i++
+	log := c.logger
+	log.Info("reloading", "count", len(c.reloadFns))
 
-				if i == 3 {
-					// cancel the handler. next sigint will force an exit
-					signal.Reset(os.Interrupt)
-				}
-			}
-		}()
+	var errs []error
+	for _, fn := range c.reloadFns {
+		err := fn(c)
+		if err != nil {
+			log.Error("reload function error", "error", err.Error())
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ShutdownSignals are the signals that trigger a graceful shutdown. Change it
+// before NewLifecycle or ProvideShutdownContext is called, e.g. to add
+// syscall.SIGQUIT.
+var ShutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+var exitCtx *ShutdownContext
+var exitCtxOnce sync.Once
+
+// ProvideShutdownContext returns the ShutdownContext of the process-wide
+// Lifecycle, started on the first call. GracefulExit exits with it.
+func ProvideShutdownContext(log *slog.Logger) (*ShutdownContext, error) {
+	// enforce that exitCtx is initialized once
+	exitCtxOnce.Do(func() {
+		lifecycle := NewLifecycle(log)
+		lifecycle.Start()
 
-		go func() {
-			<-exitCtx.Done()
-			exitCtx.doExit()
-		}()
+		exitCtx = lifecycle.ShutdownContext
 	})
 
 	return exitCtx, nil
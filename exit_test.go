@@ -0,0 +1,124 @@
+package goo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitFnPriority(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &ShutdownContext{Context: context.Background(), logger: slog.Default()}
+
+	var order []string
+	record := func(name string) func() error {
+		return func() error {
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	c.OnExitWithPriority(ExitPhaseClose, record("close db"))
+	c.OnExit(record("default 1"))
+	c.OnExitWithPriority(ExitPhaseDrain, record("drain http"))
+	c.OnExit(record("default 2"))
+	c.OnExitWithPriority(ExitPhaseFlush, record("flush logs"))
+
+	c.runExitFns()
+
+	assert.Equal([]string{"drain http", "flush logs", "default 1", "default 2", "close db"}, order)
+}
+
+func TestExitFnContext(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &ShutdownContext{Context: context.Background(), logger: slog.Default(), ExitTimeout: 20 * time.Millisecond}
+
+	var errs []error
+	c.OnExitContext(func(ctx context.Context) error {
+		deadline, ok := ctx.Deadline()
+		assert.True(ok)
+		assert.WithinDuration(time.Now().Add(20*time.Millisecond), deadline, 10*time.Millisecond)
+
+		<-ctx.Done()
+		return nil
+	})
+	c.OnExitContextWithPriority(ExitPhaseClose, func(ctx context.Context) error {
+		// the budget is shared, and already used up
+		errs = append(errs, ctx.Err())
+		return nil
+	})
+
+	c.runExitFns()
+
+	assert.Equal([]error{context.DeadlineExceeded}, errs)
+}
+
+func TestReload(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &ShutdownContext{Context: context.Background(), logger: slog.Default()}
+
+	var reloads int
+	c.OnReload(func(ctx context.Context) error {
+		reloads++
+		return nil
+	})
+	c.OnReload(func(ctx context.Context) error {
+		return errors.New("bad config")
+	})
+
+	assert.EqualError(c.Reload(), "bad config")
+	assert.Error(c.Reload())
+	assert.Equal(2, reloads)
+}
+
+func TestExitCode(t *testing.T) {
+	assert := assert.New(t)
+
+	usage := &ExitError{Code: 2, Err: errors.New("unknown flag")}
+
+	assert.Equal(0, ExitCode(nil))
+	assert.Equal(1, ExitCode(errors.New("failed")))
+	assert.Equal(2, ExitCode(usage))
+	assert.Equal(2, ExitCode(fmt.Errorf("run: %w", usage)))
+	assert.Equal("unknown flag", usage.Error())
+	assert.Equal("exit status 3", (&ExitError{Code: 3}).Error())
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	c := &ShutdownContext{Context: ctx, cancel: cancel}
+	c.ShutdownWithError(fmt.Errorf("consumer: %w", &ExitError{Code: 3}))
+	assert.Equal(3, c.exitCode())
+}
+
+func TestBoot(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &ShutdownContext{Context: context.Background(), logger: slog.Default()}
+
+	var ran []string
+	c.OnStart(func(ctx context.Context) error {
+		ran = append(ran, "warm cache")
+		return nil
+	})
+	c.OnStart(func(ctx context.Context) error {
+		return errors.New("migration failed")
+	})
+	c.OnStart(func(ctx context.Context) error {
+		ran = append(ran, "never")
+		return nil
+	})
+
+	assert.EqualError(c.Boot(), "start: migration failed")
+	assert.Equal([]string{"warm cache"}, ran)
+
+	// boots once
+	assert.NoError(c.Boot())
+	assert.Equal([]string{"warm cache"}, ran)
+}
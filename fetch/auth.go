@@ -0,0 +1,50 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// BasicAuth is a username and password for HTTP basic authentication.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// TokenSource provides bearer tokens that may be refreshed over time.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// hasAuth reports whether any of the auth fields is set.
+func (o *Options) hasAuth() bool {
+	return o.BearerToken != "" || o.BasicAuth != nil || o.TokenSource != nil
+}
+
+// setAuth sets the Authorization header of the request from the auth fields.
+// TokenSource takes precedence over BearerToken, which takes precedence over
+// BasicAuth.
+func (o *Options) setAuth(req *http.Request) error {
+	switch {
+	case o.TokenSource != nil:
+		token, err := o.TokenSource.Token(req.Context())
+		if err != nil {
+			return fmt.Errorf("fetch token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case o.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+o.BearerToken)
+	case o.BasicAuth != nil:
+		req.SetBasicAuth(o.BasicAuth.Username, o.BasicAuth.Password)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package fetch
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchRequest is a request executed by Batch.
+type BatchRequest struct {
+	Method   string
+	Resource string
+	Options  *Options
+}
+
+// BatchResult is the result of a BatchRequest.
+type BatchResult struct {
+	Response *JSONResponse
+	Err      error
+}
+
+// Batch executes JSON requests with at most concurrency requests in flight,
+// and returns the results in the order of the requests.
+//
+// Requests without a Context use ctx, so cancelling ctx (e.g. the
+// goo.ShutdownContext) aborts requests in flight. Requests not yet started
+// when ctx is cancelled fail with ctx.Err().
+func Batch(ctx context.Context, requests []BatchRequest, concurrency int) []BatchResult {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]BatchResult, len(requests))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, req BatchRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			opts := &Options{}
+			if req.Options != nil {
+				copied := *req.Options
+				opts = &copied
+			}
+
+			if opts.Context == nil {
+				opts.Context = ctx
+			}
+
+			res, err := JSON(req.Method, req.Resource, opts)
+			results[i] = BatchResult{Response: res, Err: err}
+		}(i, req)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// Batch executes JSON requests merged with the options, with at most
+// concurrency requests in flight.
+func (o *Options) Batch(ctx context.Context, requests []BatchRequest, concurrency int) []BatchResult {
+	merged := make([]BatchRequest, len(requests))
+	for i, req := range requests {
+		var opts Options
+		if req.Options != nil {
+			opts = *req.Options
+		}
+
+		merged[i] = BatchRequest{
+			Method:   req.Method,
+			Resource: req.Resource,
+			Options:  o.Merge(&opts),
+		}
+	}
+
+	return Batch(ctx, merged, concurrency)
+}
@@ -0,0 +1,144 @@
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for requests to a host whose circuit is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+const (
+	defaultBreakerThreshold    = 5
+	defaultBreakerOpenDuration = 30 * time.Second
+)
+
+// CircuitBreaker fails requests fast to hosts that keep failing.
+//
+// After Threshold consecutive failures, the circuit for the host opens and
+// requests fail with ErrCircuitOpen. Once OpenDuration has passed, a single
+// probe request is let through (half-open): if it succeeds the circuit closes,
+// otherwise it opens again.
+//
+// A CircuitBreaker is safe for concurrent use, and should be shared by the
+// Options of a client.
+type CircuitBreaker struct {
+	// Threshold is the number of consecutive failures that opens the circuit.
+	// Defaults to 5.
+	Threshold int
+	// OpenDuration is how long the circuit stays open before a probe request
+	// is let through. Defaults to 30 seconds.
+	OpenDuration time.Duration
+	// IsFailure classifies the result of a request. Defaults to transport
+	// errors and 5xx responses.
+	IsFailure func(res *http.Response, err error) bool
+
+	mu    sync.Mutex
+	hosts map[string]*circuit
+}
+
+type circuit struct {
+	failures int
+	openedAt time.Time // zero if closed
+	probing  bool
+}
+
+// NewCircuitBreaker creates a CircuitBreaker.
+func NewCircuitBreaker(threshold int, openDuration time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Threshold: threshold, OpenDuration: openDuration}
+}
+
+func (b *CircuitBreaker) threshold() int {
+	if b.Threshold <= 0 {
+		return defaultBreakerThreshold
+	}
+
+	return b.Threshold
+}
+
+func (b *CircuitBreaker) openDuration() time.Duration {
+	if b.OpenDuration <= 0 {
+		return defaultBreakerOpenDuration
+	}
+
+	return b.OpenDuration
+}
+
+func (b *CircuitBreaker) isFailure(res *http.Response, err error) bool {
+	if b.IsFailure != nil {
+		return b.IsFailure(res, err)
+	}
+
+	return err != nil || res.StatusCode >= 500
+}
+
+// allow reports whether a request to host may be sent. A request let through
+// while half-open is the probe.
+func (b *CircuitBreaker) allow(host string) (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.hosts == nil {
+		b.hosts = map[string]*circuit{}
+	}
+
+	c, ok := b.hosts[host]
+	if !ok {
+		c = &circuit{}
+		b.hosts[host] = c
+	}
+
+	if c.openedAt.IsZero() {
+		return false, nil
+	}
+
+	if c.probing || time.Since(c.openedAt) < b.openDuration() {
+		return false, fmt.Errorf("fetch %s: %w", host, ErrCircuitOpen)
+	}
+
+	c.probing = true
+	return true, nil
+}
+
+// record updates the circuit of host with the result of a request.
+func (b *CircuitBreaker) record(host string, probe, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.hosts[host]
+	if probe {
+		c.probing = false
+	}
+
+	if !failed {
+		c.failures = 0
+		c.openedAt = time.Time{}
+		return
+	}
+
+	c.failures++
+	if probe || c.failures >= b.threshold() {
+		c.openedAt = time.Now()
+	}
+}
+
+func (b *CircuitBreaker) interceptor() Interceptor {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			host := req.URL.Host
+
+			probe, err := b.allow(host)
+			if err != nil {
+				return nil, err
+			}
+
+			res, err := next(req)
+			b.record(host, probe, b.isFailure(res, err))
+
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Builder builds base Options with a fluent API:
+//
+//	api, err := fetch.New().
+//		BaseURL("https://api.example.com").
+//		Header("Accept", "application/json").
+//		Bearer(token).
+//		Build()
+//
+// Build validates the fields, and returns a copy that later calls on the
+// builder don't change.
+type Builder struct {
+	opts Options
+}
+
+// New returns an empty Builder.
+func New() *Builder {
+	return &Builder{}
+}
+
+// BaseURL sets the URL prepended to resources.
+func (b *Builder) BaseURL(baseURL string) *Builder {
+	b.opts.BaseURL = baseURL
+	return b
+}
+
+// Header adds a header sent with every request.
+func (b *Builder) Header(key, value string) *Builder {
+	if b.opts.Header == nil {
+		b.opts.Header = http.Header{}
+	}
+
+	b.opts.Header.Add(key, value)
+	return b
+}
+
+// Bearer sets the bearer token of the Authorization header.
+func (b *Builder) Bearer(token string) *Builder {
+	b.opts.BearerToken = token
+	return b
+}
+
+// BasicAuth sets the username and password of the Authorization header.
+func (b *Builder) BasicAuth(username, password string) *Builder {
+	b.opts.BasicAuth = &BasicAuth{Username: username, Password: password}
+	return b
+}
+
+// TokenSource sets the source of refreshable bearer tokens.
+func (b *Builder) TokenSource(ts TokenSource) *Builder {
+	b.opts.TokenSource = ts
+	return b
+}
+
+// Client sets the http.Client used to send requests.
+func (b *Builder) Client(client *http.Client) *Builder {
+	b.opts.Client = client
+	return b
+}
+
+// Timeout sets the timeout of the whole request.
+func (b *Builder) Timeout(timeout time.Duration) *Builder {
+	b.opts.Timeout = timeout
+	return b
+}
+
+// Interceptor appends interceptors run for every request.
+func (b *Builder) Interceptor(interceptors ...Interceptor) *Builder {
+	b.opts.Interceptors = append(b.opts.Interceptors, interceptors...)
+	return b
+}
+
+// Logger sets the logger for debug output.
+func (b *Builder) Logger(logger *slog.Logger) *Builder {
+	b.opts.Logger = logger
+	return b
+}
+
+// With calls fn to set fields that have no builder method.
+func (b *Builder) With(fn func(opts *Options)) *Builder {
+	fn(&b.opts)
+	return b
+}
+
+// Build validates the options and returns a copy of them. The maps and
+// slices, e.g. Header and QueryParams, are copied too, so that later calls on
+// the builder don't change the built options. To derive options from the
+// built ones, use Merge.
+func (b *Builder) Build() (*Options, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	opts := b.opts
+	opts.Header = b.opts.Header.Clone()
+	opts.QueryParams = cloneQuery(b.opts.QueryParams)
+	opts.PathParams = cloneQuery(b.opts.PathParams)
+	opts.BodyParams = cloneQuery(b.opts.BodyParams)
+	opts.Certificates = slices.Clone(b.opts.Certificates)
+	opts.ProxyHeader = b.opts.ProxyHeader.Clone()
+	opts.Interceptors = slices.Clone(b.opts.Interceptors)
+	opts.Codecs = maps.Clone(b.opts.Codecs)
+
+	if b.opts.BasicAuth != nil {
+		auth := *b.opts.BasicAuth
+		opts.BasicAuth = &auth
+	}
+
+	return &opts, nil
+}
+
+func (b *Builder) validate() error {
+	var errs []error
+
+	if b.opts.BaseURL != "" && !strings.HasPrefix(b.opts.BaseURL, unixScheme) {
+		u, err := url.Parse(b.opts.BaseURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid BaseURL: %w", err))
+		} else if u.Scheme != "http" && u.Scheme != "https" {
+			errs = append(errs, fmt.Errorf("invalid BaseURL %q: scheme must be http, https or unix", b.opts.BaseURL))
+		}
+	}
+
+	var auths int
+	for _, set := range []bool{b.opts.BearerToken != "", b.opts.BasicAuth != nil, b.opts.TokenSource != nil} {
+		if set {
+			auths++
+		}
+	}
+	if auths > 1 {
+		errs = append(errs, errors.New("only one of BearerToken, BasicAuth and TokenSource may be set"))
+	}
+
+	if b.opts.Timeout < 0 || b.opts.ResponseHeaderTimeout < 0 || b.opts.AttemptTimeout < 0 {
+		errs = append(errs, errors.New("timeouts must not be negative"))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("fetch: %w", err)
+	}
+
+	return nil
+}
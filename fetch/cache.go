@@ -0,0 +1,116 @@
+package fetch
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// CacheEntry is a cached response, with the validators used to revalidate it.
+type CacheEntry struct {
+	ETag         string
+	LastModified string
+
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Cache stores response bodies keyed by request URL.
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+}
+
+// MemoryCache is an in-memory Cache safe for concurrent use.
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]*CacheEntry
+}
+
+// NewMemoryCache creates an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]*CacheEntry{}}
+}
+
+func (c *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+func (c *MemoryCache) Set(key string, entry *CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = entry
+}
+
+// cacheInterceptor revalidates GET requests with If-None-Match and
+// If-Modified-Since, and replays the cached body when the server responds with
+// 304 Not Modified.
+func cacheInterceptor(cache Cache) Interceptor {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet {
+				return next(req)
+			}
+
+			key := req.URL.String()
+
+			entry, cached := cache.Get(key)
+			if cached {
+				if entry.ETag != "" && req.Header.Get("If-None-Match") == "" {
+					req.Header.Set("If-None-Match", entry.ETag)
+				}
+
+				if entry.LastModified != "" && req.Header.Get("If-Modified-Since") == "" {
+					req.Header.Set("If-Modified-Since", entry.LastModified)
+				}
+			}
+
+			res, err := next(req)
+			if err != nil {
+				return nil, err
+			}
+
+			if res.StatusCode == http.StatusNotModified && cached {
+				res.Body.Close()
+
+				res.StatusCode = entry.StatusCode
+				res.Status = strconv.Itoa(entry.StatusCode) + " " + http.StatusText(entry.StatusCode)
+				res.Header = entry.Header.Clone()
+				res.ContentLength = int64(len(entry.Body))
+				res.Body = io.NopCloser(bytes.NewReader(entry.Body))
+				return res, nil
+			}
+
+			etag := res.Header.Get("ETag")
+			lastModified := res.Header.Get("Last-Modified")
+			if res.StatusCode != http.StatusOK || (etag == "" && lastModified == "") {
+				return res, nil
+			}
+
+			body, err := io.ReadAll(res.Body)
+			res.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			cache.Set(key, &CacheEntry{
+				ETag:         etag,
+				LastModified: lastModified,
+				StatusCode:   res.StatusCode,
+				Header:       res.Header.Clone(),
+				Body:         body,
+			})
+
+			res.Body = io.NopCloser(bytes.NewReader(body))
+			return res, nil
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package fetch
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+)
+
+const defaultMaxRedirects = 10
+
+// NewCookieJar returns an empty in-memory cookie jar.
+func NewCookieJar() http.CookieJar {
+	// cookiejar.New only fails on invalid options
+	jar, _ := cookiejar.New(nil)
+	return jar
+}
+
+// NewClient builds an http.Client configured by the client settings of opts
+// (CookieJar, the redirect policy and the transport options). It starts from
+// a shallow copy of opts.Client if set.
+//
+// When transport options are set, Do builds a new transport for every
+// request. To reuse connections, build the client once:
+//
+//	opts.Client, err = fetch.NewClient(opts)
+//
+// Requests with other transport options than the client was built with, e.g.
+// a per-call TLSConfig, still get a transport derived for the request.
+func NewClient(opts *Options) (*http.Client, error) {
+	var client http.Client
+	if opts.Client != nil {
+		client = *opts.Client
+	}
+
+	if opts.CookieJar != nil {
+		client.Jar = opts.CookieJar
+	}
+
+	if opts.hasRedirectPolicy() {
+		client.CheckRedirect = opts.checkRedirect
+	}
+
+	if opts.needsTransport() {
+		t, err := opts.newTransport()
+		if err != nil {
+			return nil, err
+		}
+		client.Transport = t
+	}
+
+	return &client, nil
+}
+
+// needsTransport reports whether a transport has to be built for the
+// transport options. A client built by NewClient with the same options
+// already has them applied.
+func (o *Options) needsTransport() bool {
+	if !o.hasTransportOptions() {
+		return false
+	}
+
+	if o.Client != nil {
+		if t, ok := o.Client.Transport.(*transport); ok {
+			return !t.settings.equal(o.transportSettings())
+		}
+	}
+
+	return true
+}
+
+func (o *Options) hasRedirectPolicy() bool {
+	return o.NoRedirects || o.MaxRedirects > 0 || o.OnRedirect != nil
+}
+
+// checkRedirect implements http.Client.CheckRedirect with the redirect options.
+func (o *Options) checkRedirect(req *http.Request, via []*http.Request) error {
+	if o.NoRedirects {
+		return http.ErrUseLastResponse
+	}
+
+	max := o.MaxRedirects
+	if max <= 0 {
+		max = defaultMaxRedirects
+	}
+
+	if len(via) >= max {
+		return fmt.Errorf("stopped after %d redirects", max)
+	}
+
+	if o.OnRedirect != nil {
+		return o.OnRedirect(req, via)
+	}
+
+	return nil
+}
+
+// httpClient returns the client used to send requests. If the client has a
+// transport built for this request, release closes its idle connections.
+func (o *Options) httpClient() (client *http.Client, release func(), err error) {
+	release = func() {}
+
+	if o.CookieJar == nil && !o.hasRedirectPolicy() && !o.needsTransport() {
+		if o.Client != nil {
+			return o.Client, release, nil
+		}
+
+		return http.DefaultClient, release, nil
+	}
+
+	client, err = NewClient(o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if o.needsTransport() {
+		release = client.CloseIdleConnections
+	}
+
+	return client, release, nil
+}
@@ -0,0 +1,67 @@
+package fetch
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"strings"
+)
+
+// Codec marshals request bodies and unmarshals response bodies of a content
+// type.
+type Codec struct {
+	Marshal   func(v any) ([]byte, error)
+	Unmarshal func(data []byte, v any) error
+}
+
+// Codecs maps media types (e.g. "application/x-protobuf") to codecs.
+type Codecs map[string]Codec
+
+var (
+	JSONCodec = Codec{Marshal: json.Marshal, Unmarshal: json.Unmarshal}
+	XMLCodec  = Codec{Marshal: xml.Marshal, Unmarshal: xml.Unmarshal}
+)
+
+// DefaultCodecs are the codecs used for content types not in Options.Codecs.
+// Media types with a +json or +xml suffix use the JSON or XML codec.
+var DefaultCodecs = Codecs{
+	"application/json": JSONCodec,
+	"application/xml":  XMLCodec,
+	"text/xml":         XMLCodec,
+}
+
+// mediaType returns the media type of a Content-Type header, or "".
+func mediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	return mediaType
+}
+
+// codec returns the codec for the content type, looked up in the options
+// codecs, then the default codecs.
+func (o *Options) codec(contentType string) (Codec, bool) {
+	mediaType := mediaType(contentType)
+	if mediaType == "" {
+		return Codec{}, false
+	}
+
+	if codec, ok := o.Codecs[mediaType]; ok {
+		return codec, true
+	}
+
+	if codec, ok := DefaultCodecs[mediaType]; ok {
+		return codec, true
+	}
+
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return JSONCodec, true
+	case strings.HasSuffix(mediaType, "+xml"):
+		return XMLCodec, true
+	}
+
+	return Codec{}, false
+}
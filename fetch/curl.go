@@ -0,0 +1,74 @@
+package fetch
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// CurlCommand returns a curl command equivalent to the request. Values of
+// sensitive headers (Authorization, Cookie, API keys...) are redacted.
+//
+// The body is included if it can be read again with req.GetBody, which is the
+// case for bodies rendered from Options.Body. A streamed body is elided.
+func CurlCommand(req *http.Request) string {
+	return curlCommand(req, nil)
+}
+
+func curlCommand(req *http.Request, redact *Redact) string {
+	var b strings.Builder
+
+	b.WriteString("curl -X ")
+	b.WriteString(req.Method)
+	b.WriteString(" ")
+	b.WriteString(shellQuote(req.URL.String()))
+
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range req.Header[key] {
+			if redact.header(key) {
+				value = redacted
+			}
+
+			b.WriteString(" -H ")
+			b.WriteString(shellQuote(key + ": " + value))
+		}
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			b.WriteString(" --data-binary @-")
+		} else if body, err := req.GetBody(); err == nil {
+			data, err := io.ReadAll(body)
+			body.Close()
+			if err == nil {
+				b.WriteString(" --data-binary ")
+				b.WriteString(shellQuote(string(redact.Body(data))))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// curlInterceptor logs every request as a curl command at debug level.
+func curlInterceptor(log *slog.Logger, redact *Redact) Interceptor {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			log.Debug("fetch.curl", "curl", curlCommand(req, redact))
+			return next(req)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package fetch
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurlCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := &Options{
+		BaseURL:     "https://api.example.com",
+		Body:        `{"name": "it's"}`,
+		BearerToken: "secret",
+	}
+	opts.SetHeader("Content-Type", "application/json")
+
+	req, err := NewRequest(http.MethodPost, "/users", opts)
+	assert.NoError(err)
+
+	assert.Equal(
+		`curl -X POST 'https://api.example.com/users'`+
+			` -H 'Authorization: [REDACTED]'`+
+			` -H 'Content-Type: application/json'`+
+			` --data-binary '{"name": "it'\''s"}'`,
+		CurlCommand(req))
+}
+
+func TestRedact(t *testing.T) {
+	assert := assert.New(t)
+
+	redact := &Redact{
+		Headers:   []string{"X-Secret"},
+		BodyPaths: []string{"password", "credentials.token", "missing"},
+	}
+
+	opts := &Options{
+		BaseURL: "https://api.example.com",
+		Body:    `{"user":"alice","password":"hunter2","credentials":{"token":"abc"}}`,
+		Redact:  redact,
+	}
+	opts.SetHeader("X-Secret", "s3cr3t")
+	opts.SetHeader("X-Api-Key", "key")
+
+	req, err := NewRequest(http.MethodPost, "/login", opts)
+	assert.NoError(err)
+
+	assert.Equal(
+		`curl -X POST 'https://api.example.com/login'`+
+			` -H 'X-Api-Key: [REDACTED]'`+
+			` -H 'X-Secret: [REDACTED]'`+
+			` --data-binary '{"user":"alice","password":"[REDACTED]","credentials":{"token":"[REDACTED]"}}'`,
+		curlCommand(req, redact))
+
+	assert.Equal(http.Header{"X-Secret": {"[REDACTED]"}, "Accept": {"*/*"}},
+		redact.Header(http.Header{"X-Secret": {"s3cr3t"}, "Accept": {"*/*"}}))
+
+	assert.Equal([]byte("not json"), redact.Body([]byte("not json")))
+
+	var nilRedact *Redact
+	assert.Equal([]byte(`{"password":"x"}`), nilRedact.Body([]byte(`{"password":"x"}`)))
+}
@@ -0,0 +1,62 @@
+package fetch
+
+import (
+	"context"
+
+	"github.com/hayeah/goo/fetch/sse"
+)
+
+// defaultEventType is the type of events without an event field.
+const defaultEventType = "message"
+
+// Register sets the handler of the events of a type, run by Run. Events
+// without an event field have the type "message", and "*" handles the events
+// of types without a handler.
+func (r *SSEResponse) Register(event string, handler func(ev sse.ServerSentEvent) error) {
+	if r.handlers == nil {
+		r.handlers = map[string]func(ev sse.ServerSentEvent) error{}
+	}
+
+	r.handlers[event] = handler
+}
+
+// Run dispatches the events of the stream to the registered handlers until
+// the stream ends, a handler returns an error, or ctx is done. Events without
+// a handler are skipped. The response is closed when Run returns.
+func (r *SSEResponse) Run(ctx context.Context) error {
+	defer r.Close()
+
+	// closing the body unblocks a pending read
+	stop := context.AfterFunc(ctx, func() {
+		r.Close()
+	})
+	defer stop()
+
+	for r.Next() {
+		ev := r.Event()
+
+		event := ev.Event
+		if event == "" {
+			event = defaultEventType
+		}
+
+		handler, ok := r.handlers[event]
+		if !ok {
+			handler, ok = r.handlers["*"]
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err := handler(ev); err != nil {
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.Err()
+}
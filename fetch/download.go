@@ -0,0 +1,130 @@
+package fetch
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Download creates a new request and streams the response body to destPath.
+//
+// The body is written to destPath + ".part" and renamed when complete. If a
+// partial file is left from an interrupted download, Download asks the server
+// to resume with a Range request, and starts over if the server doesn't
+// support it.
+//
+// Options.Progress, if set, is called as the body is written.
+func Download(method, resource string, opts *Options, destPath string) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	partPath := destPath + ".part"
+
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	reqOpts := *opts
+	if offset > 0 {
+		reqOpts.Header = opts.Header.Clone()
+		reqOpts.SetHeader("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	res, err := reqOpts.Do(method, resource)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+
+		return &JSONError{JSONResponse: &JSONResponse{response: res, body: body}}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY
+	total := res.ContentLength
+
+	if res.StatusCode == http.StatusPartialContent && offset > 0 {
+		flags |= os.O_APPEND
+		total = contentRangeTotal(res.Header.Get("Content-Range"), offset, res.ContentLength)
+	} else {
+		// the server sent the whole body
+		flags |= os.O_TRUNC
+		offset = 0
+	}
+
+	f, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+	defer f.Close()
+
+	var w io.Writer = f
+	if opts.Progress != nil {
+		w = &progressWriter{w: f, written: offset, total: total, progress: opts.Progress}
+	}
+
+	_, err = io.Copy(w, res.Body)
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	err = os.Rename(partPath, destPath)
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+
+	return nil
+}
+
+// Download creates a new request and streams the response body to destPath.
+func (o *Options) Download(method, resource string, opts *Options, destPath string) error {
+	opts2 := o.Merge(opts)
+	return Download(method, resource, opts2, destPath)
+}
+
+// contentRangeTotal returns the complete length from a "bytes 100-199/200"
+// Content-Range header, or -1 if it is unknown.
+func contentRangeTotal(contentRange string, offset, contentLength int64) int64 {
+	_, size, ok := strings.Cut(contentRange, "/")
+	if ok && size != "*" {
+		total, err := strconv.ParseInt(size, 10, 64)
+		if err == nil {
+			return total
+		}
+	}
+
+	if contentLength >= 0 {
+		return offset + contentLength
+	}
+
+	return -1
+}
+
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	p.progress(p.written, p.total)
+	return n, err
+}
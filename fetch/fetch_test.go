@@ -2,15 +2,31 @@ package fetch_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/xml"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
 	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
 
 	"github.com/hayeah/goo/fetch"
+	"github.com/hayeah/goo/fetch/sse"
 )
 
 func TestOptionsCloneAndMerge(t *testing.T) {
@@ -362,3 +378,1118 @@ func TestJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeouts(t *testing.T) {
+	assert := assert.New(t)
+
+	slow := func(delay time.Duration) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+			w.Write([]byte(`{"ok": true}`))
+		}
+	}
+
+	t.Run("Timeout exceeded", func(t *testing.T) {
+		server := httptest.NewServer(slow(time.Second))
+		defer server.Close()
+
+		_, err := fetch.JSON(http.MethodGet, "/test", &fetch.Options{
+			BaseURL: server.URL,
+			Timeout: 50 * time.Millisecond,
+		})
+		assert.ErrorIs(err, context.DeadlineExceeded)
+	})
+
+	t.Run("ResponseHeaderTimeout exceeded", func(t *testing.T) {
+		server := httptest.NewServer(slow(time.Second))
+		defer server.Close()
+
+		_, err := fetch.JSON(http.MethodGet, "/test", &fetch.Options{
+			BaseURL:               server.URL,
+			ResponseHeaderTimeout: 50 * time.Millisecond,
+		})
+		assert.ErrorIs(err, fetch.ErrResponseHeaderTimeout)
+	})
+
+	t.Run("within timeouts", func(t *testing.T) {
+		server := httptest.NewServer(slow(10 * time.Millisecond))
+		defer server.Close()
+
+		res, err := fetch.JSON(http.MethodGet, "/test", &fetch.Options{
+			BaseURL:               server.URL,
+			Timeout:               time.Second,
+			ResponseHeaderTimeout: time.Second,
+		})
+		assert.NoError(err)
+		assert.True(res.Get("ok").Bool())
+	})
+
+	t.Run("AttemptTimeout with retries", func(t *testing.T) {
+		server := httptest.NewServer(slow(time.Second))
+		defer server.Close()
+
+		var attempts int
+		retry := func(next fetch.RoundTripFunc) fetch.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				for {
+					attempts++
+					res, err := next(req)
+					if err == nil || req.Context().Err() != nil {
+						return res, err
+					}
+				}
+			}
+		}
+
+		start := time.Now()
+		_, err := fetch.JSON(http.MethodGet, "/test", &fetch.Options{
+			BaseURL:        server.URL,
+			Timeout:        250 * time.Millisecond,
+			AttemptTimeout: 50 * time.Millisecond,
+			Interceptors:   []fetch.Interceptor{retry},
+		})
+		assert.ErrorIs(err, context.DeadlineExceeded)
+		assert.Greater(attempts, 2)
+		assert.Less(time.Since(start), time.Second)
+	})
+}
+
+func TestInterceptors(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"auth": "` + r.Header.Get("Authorization") + `"}`))
+	}))
+	defer server.Close()
+
+	var calls []string
+	record := func(name string) fetch.Interceptor {
+		return func(next fetch.RoundTripFunc) fetch.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				return next(req)
+			}
+		}
+	}
+
+	auth := func(next fetch.RoundTripFunc) fetch.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("Authorization", "token")
+			return next(req)
+		}
+	}
+
+	base := &fetch.Options{
+		BaseURL:      server.URL,
+		Interceptors: []fetch.Interceptor{record("base"), auth},
+	}
+
+	res, err := base.JSON(http.MethodGet, "/test", &fetch.Options{
+		Interceptors: []fetch.Interceptor{record("call")},
+	})
+	assert.NoError(err)
+	assert.Equal("token", res.Get("auth").String())
+	assert.Equal([]string{"base", "call"}, calls)
+
+	// base interceptors are not accumulated across calls
+	calls = nil
+	_, err = base.JSON(http.MethodGet, "/test", nil)
+	assert.NoError(err)
+	assert.Equal([]string{"base"}, calls)
+}
+
+func TestJSONAs(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "not found"}`))
+			return
+		}
+		w.Write([]byte(`{"id": 1, "name": "alice"}`))
+	}))
+	defer server.Close()
+
+	type User struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	user, res, err := fetch.JSONAs[User](http.MethodGet, "/user", &fetch.Options{BaseURL: server.URL})
+	assert.NoError(err)
+	assert.Equal(&User{ID: 1, Name: "alice"}, user)
+	assert.Equal(http.StatusOK, res.Response().StatusCode)
+
+	user, res, err = fetch.JSONAs[User](http.MethodGet, "/missing", &fetch.Options{BaseURL: server.URL})
+	assert.Error(err)
+	assert.Nil(user)
+	assert.Equal(`{"error": "not found"}`, res.String())
+}
+
+func TestCache(t *testing.T) {
+	assert := assert.New(t)
+
+	var hits, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"data": 42}`))
+	}))
+	defer server.Close()
+
+	opts := &fetch.Options{BaseURL: server.URL, Cache: fetch.NewMemoryCache()}
+
+	for i := 0; i < 3; i++ {
+		res, err := opts.JSON(http.MethodGet, "/test", nil)
+		assert.NoError(err)
+		assert.Equal(http.StatusOK, res.Response().StatusCode)
+		assert.Equal(int64(42), res.Get("data").Int())
+	}
+
+	assert.Equal(3, hits)
+	assert.Equal(2, notModified)
+}
+
+func TestCookieJar(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret", Path: "/"})
+			w.Write([]byte(`{}`))
+		case "/me":
+			cookie, err := r.Cookie("session")
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{}`))
+				return
+			}
+			w.Write([]byte(`{"session": "` + cookie.Value + `"}`))
+		}
+	}))
+	defer server.Close()
+
+	opts := &fetch.Options{BaseURL: server.URL, CookieJar: fetch.NewCookieJar()}
+
+	_, err := opts.JSON(http.MethodPost, "/login", nil)
+	assert.NoError(err)
+
+	res, err := opts.JSON(http.MethodGet, "/me", nil)
+	assert.NoError(err)
+	assert.Equal("secret", res.Get("session").String())
+}
+
+func TestAuth(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"auth": "` + r.Header.Get("Authorization") + `"}`))
+	}))
+	defer server.Close()
+
+	base := &fetch.Options{BaseURL: server.URL, BearerToken: "base-token"}
+
+	res, err := base.JSON(http.MethodGet, "/", nil)
+	assert.NoError(err)
+	assert.Equal("Bearer base-token", res.Get("auth").String())
+
+	res, err = base.JSON(http.MethodGet, "/", &fetch.Options{
+		BasicAuth: &fetch.BasicAuth{Username: "user", Password: "pass"},
+	})
+	assert.NoError(err)
+	assert.Equal("Basic dXNlcjpwYXNz", res.Get("auth").String())
+
+	res, err = base.JSON(http.MethodGet, "/", &fetch.Options{
+		TokenSource: fetch.TokenSourceFunc(func(ctx context.Context) (string, error) {
+			return "fresh-token", nil
+		}),
+	})
+	assert.NoError(err)
+	assert.Equal("Bearer fresh-token", res.Get("auth").String())
+}
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func TestUnmarshalError(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code": "not_found", "message": "no such user"}`))
+	}))
+	defer server.Close()
+
+	base := &fetch.Options{
+		BaseURL:        server.URL,
+		UnmarshalError: func() any { return &apiError{} },
+	}
+
+	var user struct{ Name string }
+	_, err := base.JSON(http.MethodGet, "/users/1", &fetch.Options{Unmarshal: &user})
+
+	var jerr *fetch.JSONError
+	assert.ErrorAs(err, &jerr)
+	assert.Equal(http.StatusNotFound, jerr.Response().StatusCode)
+
+	var aerr *apiError
+	assert.ErrorAs(err, &aerr)
+	assert.Equal("not_found", aerr.Code)
+	assert.Equal("no such user", aerr.Message)
+
+	// a pointer target
+	var target struct{ Code string }
+	_, err = fetch.JSON(http.MethodGet, "/users/1", &fetch.Options{BaseURL: server.URL, UnmarshalError: &target})
+	assert.ErrorAs(err, &jerr)
+	assert.Equal(&target, jerr.APIError)
+	assert.Equal("not_found", target.Code)
+}
+
+func TestMergeNonDestructive(t *testing.T) {
+	assert := assert.New(t)
+
+	base := &fetch.Options{
+		BaseURL: "https://api.example.com",
+		Header:  http.Header{"X-Base": {"base"}},
+	}
+
+	opts := &fetch.Options{
+		Header:      http.Header{"X-Call": {"call"}},
+		QueryParams: url.Values{"page": {"1"}},
+	}
+
+	merged := base.Merge(opts)
+	assert.Equal("https://api.example.com", merged.BaseURL)
+	assert.Equal("base", merged.Header.Get("X-Base"))
+	assert.Equal("call", merged.Header.Get("X-Call"))
+
+	// opts is unchanged, and can be merged again
+	assert.Equal("", opts.BaseURL)
+	assert.Equal(http.Header{"X-Call": {"call"}}, opts.Header)
+	assert.Equal([]string{"base"}, base.Merge(opts).Header.Values("X-Base"))
+
+	// merged values don't alias the originals
+	merged.SetHeader("X-Call", "changed")
+	merged.QueryParams.(url.Values).Set("page", "2")
+	assert.Equal("call", opts.Header.Get("X-Call"))
+	assert.Equal("1", opts.QueryParams.(url.Values).Get("page"))
+
+	merged = base.Merge(nil)
+	merged.SetHeader("X-Base", "changed")
+	assert.Equal("base", base.Header.Get("X-Base"))
+}
+
+func TestBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"auth": "` + r.Header.Get("Authorization") + `", "accept": "` + r.Header.Get("Accept") + `"}`))
+	}))
+	defer server.Close()
+
+	builder := fetch.New().
+		BaseURL(server.URL).
+		Header("Accept", "application/json").
+		Bearer("token")
+
+	api, err := builder.Build()
+	assert.NoError(err)
+
+	// later builder calls don't change the built options
+	builder.Header("Accept", "text/plain")
+	assert.Equal([]string{"application/json"}, api.Header.Values("Accept"))
+
+	query := url.Values{"page": {"1"}}
+	builder.With(func(opts *fetch.Options) { opts.QueryParams = query })
+	paged, err := builder.Build()
+	assert.NoError(err)
+	query.Set("page", "2")
+	builder.With(func(opts *fetch.Options) { opts.QueryParams.(url.Values).Set("page", "3") })
+	assert.Equal(url.Values{"page": {"1"}}, paged.QueryParams)
+
+	res, err := api.JSON(http.MethodGet, "/", nil)
+	assert.NoError(err)
+	assert.Equal("Bearer token", res.Get("auth").String())
+	assert.Equal("application/json", res.Get("accept").String())
+
+	_, err = fetch.New().BaseURL("ftp://example.com").Build()
+	assert.ErrorContains(err, "scheme must be http, https or unix")
+
+	_, err = fetch.New().Bearer("token").BasicAuth("user", "pass").Build()
+	assert.ErrorContains(err, "only one of")
+
+	_, err = fetch.New().Timeout(-time.Second).Build()
+	assert.ErrorContains(err, "must not be negative")
+}
+
+func TestNDJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte("{\"n\": 1}\n\n{\"n\": 2}\r\n{\"n\": 3}"))
+	}))
+	defer server.Close()
+
+	opts := &fetch.Options{BaseURL: server.URL}
+
+	res, err := opts.NDJSON(http.MethodGet, "/stream", nil)
+	assert.NoError(err)
+	defer res.Close()
+
+	var ns []int
+	for res.Next() {
+		var v struct{ N int }
+		assert.NoError(res.Decode(&v))
+		assert.Equal(int64(v.N), res.Get("n").Int())
+		ns = append(ns, v.N)
+	}
+
+	assert.NoError(res.Err())
+	assert.Equal([]int{1, 2, 3}, ns)
+}
+
+func TestDownload(t *testing.T) {
+	assert := assert.New(t)
+
+	content := strings.Repeat("0123456789", 1000)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file.txt", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "file.txt")
+
+	var written, total int64
+	opts := &fetch.Options{
+		BaseURL: server.URL,
+		Progress: func(w, t int64) {
+			written, total = w, t
+		},
+	}
+
+	t.Run("full download", func(t *testing.T) {
+		err := fetch.Download(http.MethodGet, "/file.txt", opts, dest)
+		assert.NoError(err)
+
+		data, err := os.ReadFile(dest)
+		assert.NoError(err)
+		assert.Equal(content, string(data))
+		assert.Equal(int64(len(content)), written)
+		assert.Equal(int64(len(content)), total)
+	})
+
+	t.Run("resume partial download", func(t *testing.T) {
+		dest := filepath.Join(dir, "resumed.txt")
+		err := os.WriteFile(dest+".part", []byte(content[:4000]), 0644)
+		assert.NoError(err)
+
+		err = fetch.Download(http.MethodGet, "/file.txt", opts, dest)
+		assert.NoError(err)
+
+		data, err := os.ReadFile(dest)
+		assert.NoError(err)
+		assert.Equal(content, string(data))
+		assert.Equal(int64(len(content)), written)
+		assert.Equal(int64(len(content)), total)
+
+		_, err = os.Stat(dest + ".part")
+		assert.True(os.IsNotExist(err))
+	})
+}
+
+func TestUploadReader(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(map[string]any{
+			"length":   r.ContentLength,
+			"chunked":  len(r.TransferEncoding) > 0 && r.TransferEncoding[0] == "chunked",
+			"received": len(body),
+		})
+	}))
+	defer server.Close()
+
+	content := strings.Repeat("x", 10000)
+
+	// hide the concrete reader type so net/http can't infer the length
+	type reader struct{ io.Reader }
+
+	t.Run("known length", func(t *testing.T) {
+		res, err := fetch.JSON(http.MethodPost, "/upload", &fetch.Options{
+			BaseURL:       server.URL,
+			Body:          reader{strings.NewReader(content)},
+			ContentLength: int64(len(content)),
+		})
+		assert.NoError(err)
+		assert.Equal(int64(len(content)), res.Get("length").Int())
+		assert.Equal(int64(len(content)), res.Get("received").Int())
+		assert.False(res.Get("chunked").Bool())
+	})
+
+	t.Run("unknown length", func(t *testing.T) {
+		res, err := fetch.JSON(http.MethodPost, "/upload", &fetch.Options{
+			BaseURL: server.URL,
+			Body:    reader{strings.NewReader(content)},
+		})
+		assert.NoError(err)
+		assert.Equal(int64(-1), res.Get("length").Int())
+		assert.Equal(int64(len(content)), res.Get("received").Int())
+		assert.True(res.Get("chunked").Bool())
+	})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	assert := assert.New(t)
+
+	var hits int
+	healthy := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	opts := &fetch.Options{
+		BaseURL:        server.URL,
+		CircuitBreaker: fetch.NewCircuitBreaker(2, 50*time.Millisecond),
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := opts.JSON(http.MethodGet, "/", nil)
+		assert.IsType(&fetch.JSONError{}, err)
+	}
+
+	// open: fails without hitting the server
+	_, err := opts.JSON(http.MethodGet, "/", nil)
+	assert.ErrorIs(err, fetch.ErrCircuitOpen)
+	assert.Equal(2, hits)
+
+	// half-open: the probe fails, and the circuit opens again
+	time.Sleep(60 * time.Millisecond)
+	_, err = opts.JSON(http.MethodGet, "/", nil)
+	assert.IsType(&fetch.JSONError{}, err)
+	_, err = opts.JSON(http.MethodGet, "/", nil)
+	assert.ErrorIs(err, fetch.ErrCircuitOpen)
+	assert.Equal(3, hits)
+
+	// half-open: the probe succeeds, and the circuit closes
+	healthy = true
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		_, err = opts.JSON(http.MethodGet, "/", nil)
+		assert.NoError(err)
+	}
+	assert.Equal(6, hits)
+}
+
+func TestResponseAccessors(t *testing.T) {
+	assert := assert.New(t)
+
+	server := fetch.NewMockServer(t)
+	server.On("GET", "/old").RespondHeader("Location", "/new").RespondJSON(http.StatusFound, `{}`)
+	server.On("GET", "/new").
+		RespondHeader("Content-Type", "application/json; charset=utf-8").
+		RespondHeader("X-RateLimit-Limit", "60").
+		RespondHeader("X-RateLimit-Remaining", "59").
+		RespondHeader("X-RateLimit-Reset", "1700000000").
+		RespondJSON(http.StatusOK, `{}`)
+	server.On("GET", "/relative").
+		RespondHeader("X-RateLimit-Remaining", "0").
+		RespondHeader("X-RateLimit-Reset", "30").
+		RespondJSON(http.StatusOK, `{}`)
+	server.On("GET", "/none").RespondJSON(http.StatusOK, `{}`)
+
+	res, err := fetch.JSON(http.MethodGet, "/old", server.Options())
+	assert.NoError(err)
+	assert.Equal(http.StatusOK, res.StatusCode())
+	assert.Equal("application/json", res.ContentType())
+	assert.Equal("59", res.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(server.URL+"/new", res.RequestURL().String())
+
+	limit, ok := res.RateLimit()
+	assert.True(ok)
+	assert.Equal(fetch.RateLimitStatus{Limit: 60, Remaining: 59, Reset: time.Unix(1700000000, 0)}, limit)
+
+	res, err = fetch.JSON(http.MethodGet, "/relative", server.Options())
+	assert.NoError(err)
+	limit, ok = res.RateLimit()
+	assert.True(ok)
+	assert.Equal(-1, limit.Limit)
+	assert.Equal(0, limit.Remaining)
+	assert.WithinDuration(time.Now().Add(30*time.Second), limit.Reset, time.Second)
+
+	res, err = fetch.JSON(http.MethodGet, "/none", server.Options())
+	assert.NoError(err)
+	_, ok = res.RateLimit()
+	assert.False(ok)
+}
+
+func TestRateLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	opts := &fetch.Options{
+		BaseURL:   server.URL,
+		RateLimit: fetch.NewRateLimiter(20, 1),
+	}
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := opts.JSON(http.MethodGet, "/", nil)
+		assert.NoError(err)
+	}
+
+	// the first request uses the burst, the next 3 wait 50ms each
+	assert.GreaterOrEqual(time.Since(start), 140*time.Millisecond)
+}
+
+func TestRedirects(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusFound)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusMovedPermanently)
+		case "/c":
+			w.Write([]byte(`{"path": "c"}`))
+		}
+	}))
+	defer server.Close()
+
+	opts := &fetch.Options{BaseURL: server.URL}
+
+	t.Run("follow", func(t *testing.T) {
+		res, err := opts.JSON(http.MethodGet, "/a", nil)
+		assert.NoError(err)
+		assert.Equal("c", res.Get("path").String())
+
+		redirects := res.Redirects()
+		assert.Len(redirects, 2)
+		assert.Equal(http.StatusFound, redirects[0].StatusCode)
+		assert.Equal("/a", redirects[0].Request.URL.Path)
+		assert.Equal(http.StatusMovedPermanently, redirects[1].StatusCode)
+		assert.Equal("/b", redirects[1].Request.URL.Path)
+	})
+
+	t.Run("NoRedirects", func(t *testing.T) {
+		res, err := opts.JSON(http.MethodGet, "/a", &fetch.Options{NoRedirects: true})
+		assert.NoError(err)
+		assert.Equal(http.StatusFound, res.Response().StatusCode)
+		assert.Equal("/b", res.Response().Header.Get("Location"))
+	})
+
+	t.Run("MaxRedirects", func(t *testing.T) {
+		_, err := opts.JSON(http.MethodGet, "/a", &fetch.Options{MaxRedirects: 1})
+		assert.ErrorContains(err, "stopped after 1 redirects")
+	})
+
+	t.Run("OnRedirect", func(t *testing.T) {
+		var seen []int
+		_, err := opts.JSON(http.MethodGet, "/a", &fetch.Options{
+			OnRedirect: func(req *http.Request, via []*http.Request) error {
+				seen = append(seen, req.Response.StatusCode)
+				return nil
+			},
+		})
+		assert.NoError(err)
+		assert.Equal([]int{http.StatusFound, http.StatusMovedPermanently}, seen)
+	})
+}
+
+func TestTLSOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"client_certs": ` + strconv.Itoa(len(r.TLS.PeerCertificates)) + `}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	t.Run("unknown CA", func(t *testing.T) {
+		_, err := fetch.JSON(http.MethodGet, "/", &fetch.Options{BaseURL: server.URL})
+		assert.Error(err)
+	})
+
+	t.Run("RootCAs", func(t *testing.T) {
+		pool := x509.NewCertPool()
+		pool.AddCert(server.Certificate())
+
+		res, err := fetch.JSON(http.MethodGet, "/", &fetch.Options{BaseURL: server.URL, RootCAs: pool})
+		assert.NoError(err)
+		assert.Equal(int64(0), res.Get("client_certs").Int())
+	})
+
+	t.Run("InsecureSkipVerify with client certificate", func(t *testing.T) {
+		opts := &fetch.Options{
+			BaseURL:            server.URL,
+			InsecureSkipVerify: true,
+			Certificates:       server.TLS.Certificates,
+			MinTLSVersion:      tls.VersionTLS12,
+		}
+
+		client, err := fetch.NewClient(opts)
+		assert.NoError(err)
+		opts.Client = client
+
+		for i := 0; i < 2; i++ {
+			res, err := fetch.JSON(http.MethodGet, "/", opts)
+			assert.NoError(err)
+			assert.Equal(int64(1), res.Get("client_certs").Int())
+		}
+	})
+}
+
+func TestUnixSocket(t *testing.T) {
+	assert := assert.New(t)
+
+	socket := filepath.Join(t.TempDir(), "test.sock")
+	listener, err := net.Listen("unix", socket)
+	assert.NoError(err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	res, err := fetch.JSON(http.MethodGet, "/containers/json", &fetch.Options{BaseURL: "unix://" + socket})
+	assert.NoError(err)
+	assert.Equal("/containers/json", res.Get("path").String())
+
+	res, err = fetch.JSON(http.MethodGet, "http://localhost/version", &fetch.Options{UnixSocket: socket})
+	assert.NoError(err)
+	assert.Equal("/version", res.Get("path").String())
+}
+
+func TestProxy(t *testing.T) {
+	assert := assert.New(t)
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"url":   r.URL.String(),
+			"proxy": r.Header.Get("Proxy-Authorization"),
+		})
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("user", "pass")
+
+	res, err := fetch.JSON(http.MethodGet, "http://api.example.com/items", &fetch.Options{Proxy: proxyURL.String()})
+	assert.NoError(err)
+	assert.Equal("http://api.example.com/items", res.Get("url").String())
+	assert.Equal("Basic dXNlcjpwYXNz", res.Get("proxy").String())
+
+	_, err = fetch.JSON(http.MethodGet, "http://api.example.com/items", &fetch.Options{Proxy: ":invalid"})
+	assert.ErrorContains(err, "invalid proxy")
+}
+
+func TestQueryTemplates(t *testing.T) {
+	assert := assert.New(t)
+
+	opts := &fetch.Options{
+		BaseURL:     "http://example.com",
+		PathParams:  map[string]any{"UserID": 42},
+		BodyParams:  map[string]any{"Since": "2024-01-01"},
+		Body:        `{"since": {{Since}}}`,
+		QueryParams: url.Values{"user": {"{{UserID}}"}, "since": {"{{Since}}"}, "limit": {"10"}},
+	}
+
+	req, err := fetch.NewRequest(http.MethodPost, "/users/{{UserID}}/events", opts)
+	assert.NoError(err)
+	assert.Equal("http://example.com/users/42/events?limit=10&since=2024-01-01&user=42", req.URL.String())
+
+	// the options are not modified
+	assert.Equal(url.Values{"user": {"{{UserID}}"}, "since": {"{{Since}}"}, "limit": {"10"}}, opts.QueryParams)
+}
+
+func TestXML(t *testing.T) {
+	assert := assert.New(t)
+
+	type Item struct {
+		XMLName xml.Name `xml:"item"`
+		ID      int      `xml:"id,attr"`
+		Name    string   `xml:"name"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<error>not found</error>`))
+			return
+		}
+
+		var item Item
+		err := xml.NewDecoder(r.Body).Decode(&item)
+		assert.NoError(err)
+		assert.Equal("application/xml", r.Header.Get("Accept"))
+
+		item.ID = 1
+		w.Header().Set("Content-Type", "application/xml")
+		xml.NewEncoder(w).Encode(item)
+	}))
+	defer server.Close()
+
+	opts := &fetch.Options{BaseURL: server.URL}
+	opts.SetHeader("Content-Type", "application/xml")
+
+	var created Item
+	res, err := opts.XML(http.MethodPost, "/items", &fetch.Options{
+		Body:      Item{Name: "widget"},
+		Unmarshal: &created,
+	})
+	assert.NoError(err)
+	assert.Equal(`<item id="1"><name>widget</name></item>`, res.String())
+	assert.Equal(Item{XMLName: xml.Name{Local: "item"}, ID: 1, Name: "widget"}, created)
+
+	_, err = opts.XML(http.MethodGet, "/items/2", nil)
+	xmlErr, ok := err.(*fetch.XMLError)
+	assert.True(ok)
+	assert.Equal(`<error>not found</error>`, xmlErr.String())
+}
+
+func TestCodecs(t *testing.T) {
+	assert := assert.New(t)
+
+	// a toy codec that encodes key=value lines
+	kv := fetch.Codec{
+		Marshal: func(v any) ([]byte, error) {
+			var lines []string
+			for key, value := range v.(map[string]string) {
+				lines = append(lines, key+"="+value)
+			}
+			sort.Strings(lines)
+			return []byte(strings.Join(lines, "\n")), nil
+		},
+		Unmarshal: func(data []byte, v any) error {
+			m := v.(*map[string]string)
+			*m = map[string]string{}
+			for _, line := range strings.Split(string(data), "\n") {
+				key, value, _ := strings.Cut(line, "=")
+				(*m)[key] = value
+			}
+			return nil
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal("a=1\nb=2", string(body))
+
+		w.Header().Set("Content-Type", "text/x-kv; charset=utf-8")
+		w.Write([]byte("c=3"))
+	}))
+	defer server.Close()
+
+	opts := &fetch.Options{
+		BaseURL: server.URL,
+		Codecs:  fetch.Codecs{"text/x-kv": kv},
+	}
+	opts.SetHeader("Content-Type", "text/x-kv")
+
+	var out map[string]string
+	res, err := opts.JSON(http.MethodPost, "/", &fetch.Options{
+		Body:      map[string]string{"a": "1", "b": "2"},
+		Unmarshal: &out,
+	})
+	assert.NoError(err)
+	assert.Equal(map[string]string{"c": "3"}, out)
+
+	var out2 map[string]string
+	assert.NoError(res.Unmarshal(&out2))
+	assert.Equal(out, out2)
+}
+
+func TestBatch(t *testing.T) {
+	assert := assert.New(t)
+
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+	}))
+	defer server.Close()
+
+	opts := &fetch.Options{BaseURL: server.URL}
+
+	var requests []fetch.BatchRequest
+	for i := 0; i < 10; i++ {
+		requests = append(requests, fetch.BatchRequest{Method: "GET", Resource: "/" + strconv.Itoa(i)})
+	}
+	requests = append(requests, fetch.BatchRequest{Method: "GET", Resource: "/fail"})
+
+	results := opts.Batch(context.Background(), requests, 3)
+	assert.Len(results, 11)
+	for i := 0; i < 10; i++ {
+		assert.NoError(results[i].Err)
+		assert.Equal("/"+strconv.Itoa(i), results[i].Response.Get("path").String())
+	}
+	assert.IsType(&fetch.JSONError{}, results[10].Err)
+	assert.LessOrEqual(maxInFlight, 3)
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results := opts.Batch(ctx, requests, 3)
+		for _, result := range results {
+			assert.ErrorIs(result.Err, context.Canceled)
+		}
+	})
+}
+
+func TestHAR(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "session.har")
+	har := fetch.NewHARRecorder(path)
+
+	opts := &fetch.Options{BaseURL: server.URL, HAR: har, BearerToken: "secret"}
+
+	_, err := opts.JSON(http.MethodPost, "/items", &fetch.Options{
+		Body:        `{"name": "widget"}`,
+		QueryParams: map[string]string{"dry": "1"},
+	})
+	assert.NoError(err)
+
+	assert.NoError(har.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(err)
+
+	log := gjson.GetBytes(data, "log")
+	assert.Equal("1.2", log.Get("version").String())
+	assert.Equal(int64(1), log.Get("entries.#").Int())
+
+	entry := log.Get("entries.0")
+	assert.Equal("POST", entry.Get("request.method").String())
+	assert.Equal(server.URL+"/items?dry=1", entry.Get("request.url").String())
+	assert.Equal(`{"name": "widget"}`, entry.Get("request.postData.text").String())
+	assert.Equal("[REDACTED]", entry.Get(`request.headers.#(name=="Authorization").value`).String())
+	assert.Equal(int64(200), entry.Get("response.status").Int())
+	assert.Equal(`{"ok": true}`, entry.Get("response.content.text").String())
+}
+
+type countingResolver struct {
+	count int
+	addrs []string
+}
+
+func (r *countingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.count++
+	return r.addrs, nil
+}
+
+func TestResolver(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"host": "` + r.Host + `"}`))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	baseURL := "http://api.example.test:" + serverURL.Port()
+
+	t.Run("StaticResolver", func(t *testing.T) {
+		res, err := fetch.JSON(http.MethodGet, "/", &fetch.Options{
+			BaseURL:  baseURL,
+			Resolver: &fetch.StaticResolver{Hosts: map[string][]string{"api.example.test": {"127.0.0.1"}}},
+		})
+		assert.NoError(err)
+		assert.Equal("api.example.test:"+serverURL.Port(), res.Get("host").String())
+
+		_, err = fetch.JSON(http.MethodGet, "/", &fetch.Options{
+			BaseURL:  "http://other.example.test",
+			Resolver: &fetch.StaticResolver{},
+		})
+		assert.ErrorContains(err, "host not pinned")
+	})
+
+	t.Run("CachingResolver", func(t *testing.T) {
+		upstream := &countingResolver{addrs: []string{"127.0.0.1"}}
+		opts := &fetch.Options{
+			BaseURL:  baseURL,
+			Resolver: &fetch.CachingResolver{Resolver: upstream, TTL: time.Minute},
+		}
+
+		for i := 0; i < 3; i++ {
+			_, err := fetch.JSON(http.MethodGet, "/", opts)
+			assert.NoError(err)
+		}
+		assert.Equal(1, upstream.count)
+	})
+}
+
+func TestSSEDispatcher(t *testing.T) {
+	assert := assert.New(t)
+
+	server := fetch.NewMockServer(t)
+	server.On("GET", "/events").RespondSSE(
+		sse.ServerSentEvent{Data: "hello"},
+		sse.ServerSentEvent{Event: "update", Data: `{"n": 1}`},
+		sse.ServerSentEvent{Event: "ping"},
+		sse.ServerSentEvent{Event: "update", Data: `{"n": 2}`},
+	).Times(2)
+
+	res, err := fetch.SSE(http.MethodGet, "/events", server.Options())
+	assert.NoError(err)
+
+	var messages []string
+	var updates []int64
+	var others []string
+	res.Register("message", func(ev sse.ServerSentEvent) error {
+		messages = append(messages, ev.Data)
+		return nil
+	})
+	res.Register("update", func(ev sse.ServerSentEvent) error {
+		updates = append(updates, ev.GJSON("n").Int())
+		return nil
+	})
+	res.Register("*", func(ev sse.ServerSentEvent) error {
+		others = append(others, ev.Event)
+		return nil
+	})
+
+	assert.NoError(res.Run(context.Background()))
+	assert.Equal([]string{"hello"}, messages)
+	assert.Equal([]int64{1, 2}, updates)
+	assert.Equal([]string{"ping"}, others)
+
+	// a handler error stops the loop
+	res, err = fetch.SSE(http.MethodGet, "/events", server.Options())
+	assert.NoError(err)
+
+	updates = nil
+	res.Register("update", func(ev sse.ServerSentEvent) error {
+		updates = append(updates, ev.GJSON("n").Int())
+		return io.ErrUnexpectedEOF
+	})
+	assert.ErrorIs(res.Run(context.Background()), io.ErrUnexpectedEOF)
+	assert.Equal([]int64{1}, updates)
+}
+
+func TestSSEDispatcherCancel(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	res, err := fetch.SSE(http.MethodGet, "/", &fetch.Options{BaseURL: server.URL})
+	assert.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	res.Register("message", func(ev sse.ServerSentEvent) error {
+		cancel()
+		return nil
+	})
+
+	assert.ErrorIs(res.Run(ctx), context.Canceled)
+}
+
+func TestWebSocket(t *testing.T) {
+	assert := assert.New(t)
+
+	closed := make(chan websocket.StatusCode, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		for {
+			var msg map[string]any
+			err := wsjson.Read(r.Context(), conn, &msg)
+			if err != nil {
+				closed <- websocket.CloseStatus(err)
+				return
+			}
+
+			msg["auth"] = r.Header.Get("Authorization")
+			msg["path"] = r.URL.Path
+			wsjson.Write(r.Context(), conn, msg)
+		}
+	}))
+	defer server.Close()
+
+	base := &fetch.Options{BaseURL: server.URL, BearerToken: "token"}
+
+	conn, err := base.WebSocket("/rooms/{{room}}", &fetch.Options{PathParams: map[string]any{"room": "a b"}})
+	assert.NoError(err)
+
+	assert.NoError(conn.WriteJSON(map[string]any{"text": "hello"}))
+
+	var reply map[string]any
+	assert.NoError(conn.ReadJSON(&reply))
+	assert.Equal(map[string]any{"text": "hello", "auth": "Bearer token", "path": "/rooms/a b"}, reply)
+
+	assert.NoError(conn.Close())
+	assert.Equal(websocket.StatusNormalClosure, <-closed)
+
+	// cancelling the context closes the connection
+	ctx, cancel := context.WithCancel(context.Background())
+	conn, err = base.WebSocket("/", &fetch.Options{Context: ctx})
+	assert.NoError(err)
+
+	cancel()
+	assert.Equal(websocket.StatusGoingAway, <-closed)
+	assert.NoError(conn.Close())
+}
@@ -0,0 +1,88 @@
+// Package fetchtest provides assertions on fetch responses, for terse API
+// client tests:
+//
+//	res, err := api.JSON("GET", "/items", nil)
+//	fetchtest.AssertStatus(t, res, 200)
+//	fetchtest.AssertGet(t, res, "data.items.#", 3)
+//	fetchtest.AssertGet(t, res, "data.items.0.name", "first")
+package fetchtest
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hayeah/goo/fetch"
+)
+
+// AssertGet asserts that the GJSON path of the response body equals expected.
+// Values are compared as JSON, so 3 equals 3.0 and structs equal the objects
+// they marshal to.
+func AssertGet(t testing.TB, res *fetch.JSONResponse, path string, expected any) bool {
+	t.Helper()
+
+	result := res.Get(path)
+	if !result.Exists() {
+		t.Errorf("fetchtest: %s: path not found in %s", path, res.String())
+		return false
+	}
+
+	want, err := json.Marshal(expected)
+	if err != nil {
+		t.Errorf("fetchtest: %s: marshal expected: %v", path, err)
+		return false
+	}
+
+	if !jsonEqual(want, []byte(result.Raw)) {
+		t.Errorf("fetchtest: %s: expected %s, got %s", path, want, result.Raw)
+		return false
+	}
+
+	return true
+}
+
+// AssertExists asserts that the GJSON path exists in the response body.
+func AssertExists(t testing.TB, res *fetch.JSONResponse, path string) bool {
+	t.Helper()
+
+	if !res.Get(path).Exists() {
+		t.Errorf("fetchtest: %s: path not found in %s", path, res.String())
+		return false
+	}
+
+	return true
+}
+
+// AssertNotExists asserts that the GJSON path doesn't exist in the response
+// body.
+func AssertNotExists(t testing.TB, res *fetch.JSONResponse, path string) bool {
+	t.Helper()
+
+	if result := res.Get(path); result.Exists() {
+		t.Errorf("fetchtest: %s: expected no value, got %s", path, result.Raw)
+		return false
+	}
+
+	return true
+}
+
+// AssertStatus asserts the status code of the response.
+func AssertStatus(t testing.TB, res *fetch.JSONResponse, status int) bool {
+	t.Helper()
+
+	if got := res.Response().StatusCode; got != status {
+		t.Errorf("fetchtest: expected status %d, got %d: %s", status, got, res.String())
+		return false
+	}
+
+	return true
+}
+
+func jsonEqual(a, b []byte) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,53 @@
+package fetchtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hayeah/goo/fetch"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	assert := assert.New(t)
+
+	server := fetch.NewMockServer(t)
+	server.On("GET", "/items").RespondJSON(200, `{"data": {"items": [{"name": "first", "tags": ["a"]}, {"name": "second"}], "total": 2.0}}`)
+
+	res, err := fetch.JSON("GET", "/items", server.Options())
+	assert.NoError(err)
+
+	assert.True(AssertStatus(t, res, 200))
+	assert.True(AssertGet(t, res, "data.items.#", 2))
+	assert.True(AssertGet(t, res, "data.total", 2))
+	assert.True(AssertGet(t, res, "data.items.0.name", "first"))
+	assert.True(AssertGet(t, res, "data.items.0", map[string]any{"name": "first", "tags": []string{"a"}}))
+	assert.True(AssertExists(t, res, "data.items.1"))
+	assert.True(AssertNotExists(t, res, "data.items.2"))
+
+	r := &recorder{TB: t}
+	assert.False(AssertStatus(r, res, 404))
+	assert.False(AssertGet(r, res, "data.items.#", 3))
+	assert.False(AssertGet(r, res, "data.missing", 1))
+	assert.False(AssertExists(r, res, "data.missing"))
+	assert.False(AssertNotExists(r, res, "data.total"))
+	assert.Equal([]string{
+		`fetchtest: expected status 404, got 200: {"data": {"items": [{"name": "first", "tags": ["a"]}, {"name": "second"}], "total": 2.0}}`,
+		"fetchtest: data.items.#: expected 3, got 2",
+		`fetchtest: data.missing: path not found in {"data": {"items": [{"name": "first", "tags": ["a"]}, {"name": "second"}], "total": 2.0}}`,
+		`fetchtest: data.missing: path not found in {"data": {"items": [{"name": "first", "tags": ["a"]}, {"name": "second"}], "total": 2.0}}`,
+		"fetchtest: data.total: expected no value, got 2.0",
+	}, r.errors)
+}
@@ -0,0 +1,271 @@
+package fetch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// HARRecorder records requests and responses, and writes them as a HAR
+// (HTTP Archive) file on Close, to be inspected in browser devtools.
+//
+//	har := fetch.NewHARRecorder("session.har")
+//	defer har.Close()
+//
+//	opts := &fetch.Options{BaseURL: "https://api.example.com", HAR: har}
+//
+// Sensitive headers are redacted. Response bodies are recorded as they are
+// read, and the entry is added when the body is closed.
+type HARRecorder struct {
+	path string
+
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+// NewHARRecorder creates a recorder that writes to path on Close.
+func NewHARRecorder(path string) *HARRecorder {
+	return &HARRecorder{path: path}
+}
+
+// Close writes the recorded entries to the HAR file.
+func (h *HARRecorder) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, err := os.Create(h.path)
+	if err != nil {
+		return fmt.Errorf("write HAR: %w", err)
+	}
+	defer f.Close()
+
+	err = h.encode(f)
+	if err != nil {
+		return fmt.Errorf("write HAR: %w", err)
+	}
+
+	return f.Close()
+}
+
+func (h *HARRecorder) encode(w io.Writer) error {
+	entries := h.entries
+	if entries == nil {
+		entries = []harEntry{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]any{
+		"log": harLog{
+			Version: "1.2",
+			Creator: harCreator{Name: "goo/fetch", Version: "1.0"},
+			Entries: entries,
+		},
+	})
+}
+
+func (h *HARRecorder) add(entry harEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, entry)
+}
+
+func (h *HARRecorder) interceptor(redact *Redact) Interceptor {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+
+			entry := harEntry{
+				StartedDateTime: start.Format(time.RFC3339Nano),
+				Request:         harRequestOf(req, redact),
+				Cache:           struct{}{},
+			}
+
+			res, err := next(req)
+			if err != nil {
+				return nil, err
+			}
+
+			wait := time.Since(start)
+			entry.Response = harResponseOf(res, redact)
+
+			res.Body = &harBody{
+				ReadCloser: res.Body,
+				done: func(body []byte) {
+					receive := time.Since(start) - wait
+
+					entry.Response.Content.Size = len(body)
+					entry.Response.Content.Text = string(redact.Body(body))
+					entry.Response.BodySize = len(body)
+					entry.Time = float64(time.Since(start)) / float64(time.Millisecond)
+					entry.Timings = harTimings{
+						Send:    0,
+						Wait:    float64(wait) / float64(time.Millisecond),
+						Receive: float64(receive) / float64(time.Millisecond),
+					}
+
+					h.add(entry)
+				},
+			}
+
+			return res, nil
+		}
+	}
+}
+
+// harBody buffers the response body as it is read, and calls done once when
+// it is closed.
+type harBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	done func(body []byte)
+	once sync.Once
+}
+
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	return n, err
+}
+
+func (b *harBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.done(b.buf.Bytes())
+	})
+	return err
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func harHeaders(header http.Header, redact *Redact) []harNameValue {
+	headers := []harNameValue{}
+	for key, values := range header {
+		for _, value := range values {
+			if redact.header(key) {
+				value = redacted
+			}
+			headers = append(headers, harNameValue{Name: key, Value: value})
+		}
+	}
+
+	return headers
+}
+
+func harRequestOf(req *http.Request, redact *Redact) harRequest {
+	query := []harNameValue{}
+	for key, values := range req.URL.Query() {
+		for _, value := range values {
+			query = append(query, harNameValue{Name: key, Value: value})
+		}
+	}
+
+	r := harRequest{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: req.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(req.Header, redact),
+		QueryString: query,
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, err := io.ReadAll(body)
+			body.Close()
+			if err == nil {
+				r.BodySize = len(data)
+				r.PostData = &harPostData{MimeType: req.Header.Get("Content-Type"), Text: string(redact.Body(data))}
+			}
+		}
+	} else if req.Body == nil || req.Body == http.NoBody {
+		r.BodySize = 0
+	}
+
+	return r
+}
+
+func harResponseOf(res *http.Response, redact *Redact) harResponse {
+	return harResponse{
+		Status:      res.StatusCode,
+		StatusText:  http.StatusText(res.StatusCode),
+		HTTPVersion: res.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(res.Header, redact),
+		Content:     harContent{MimeType: res.Header.Get("Content-Type")},
+		RedirectURL: res.Header.Get("Location"),
+		HeadersSize: -1,
+	}
+}
@@ -0,0 +1,20 @@
+package fetch
+
+import "net/http"
+
+// RoundTripFunc sends a request and returns its response.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Interceptor wraps a RoundTripFunc to add behaviour around every request,
+// e.g. injecting auth tokens, logging or collecting metrics.
+type Interceptor func(next RoundTripFunc) RoundTripFunc
+
+// chain wraps rt with the interceptors. The first interceptor is the outermost,
+// and sees the request first.
+func chain(rt RoundTripFunc, interceptors []Interceptor) RoundTripFunc {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		rt = interceptors[i](rt)
+	}
+
+	return rt
+}
@@ -0,0 +1,247 @@
+package fetch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hayeah/goo/fetch/sse"
+)
+
+// MockServer is an httptest server that responds to requests with canned
+// responses, for testing API clients.
+//
+//	server := fetch.NewMockServer(t)
+//	server.On("GET", "/users/1").RespondJSON(200, `{"id": 1}`)
+//
+//	api := NewAPIClient(server.Options())
+type MockServer struct {
+	*httptest.Server
+
+	t testing.TB
+
+	mu           sync.Mutex
+	expectations []*MockExpectation
+}
+
+// NewMockServer starts a MockServer. The server is closed when the test ends,
+// and the test fails if a registered expectation wasn't met.
+func NewMockServer(t testing.TB) *MockServer {
+	m := &MockServer{t: t}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.serveHTTP))
+
+	t.Cleanup(func() {
+		m.Close()
+		m.AssertExpectations()
+	})
+
+	return m
+}
+
+// Options returns options with BaseURL pointing at the mock server.
+func (m *MockServer) Options() *Options {
+	return &Options{
+		BaseURL: m.URL,
+		Client:  m.Client(),
+	}
+}
+
+// On registers an expected request. Expectations are matched in registration
+// order.
+func (m *MockServer) On(method, path string) *MockExpectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := &MockExpectation{
+		method: strings.ToUpper(method),
+		path:   path,
+		status: http.StatusOK,
+		header: http.Header{},
+	}
+	m.expectations = append(m.expectations, e)
+
+	return e
+}
+
+// AssertExpectations fails the test if an expectation wasn't called the
+// expected number of times.
+func (m *MockServer) AssertExpectations() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.expectations {
+		switch {
+		case e.times > 0 && e.calls != e.times:
+			m.t.Errorf("fetch mock: %s %s: expected %d calls, got %d", e.method, e.path, e.times, e.calls)
+		case e.times == 0 && e.calls == 0:
+			m.t.Errorf("fetch mock: %s %s: expected call", e.method, e.path)
+		}
+	}
+}
+
+func (m *MockServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	e := m.match(r, body)
+	if e == nil {
+		m.t.Errorf("fetch mock: unexpected request: %s %s", r.Method, r.URL)
+		http.Error(w, "unexpected request", http.StatusNotImplemented)
+		return
+	}
+
+	e.respond(w)
+}
+
+// match finds the first expectation matching the request, and counts the call.
+func (m *MockServer) match(r *http.Request, body []byte) *MockExpectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.expectations {
+		if e.times > 0 && e.calls >= e.times {
+			continue
+		}
+
+		if !e.matches(r, body) {
+			continue
+		}
+
+		e.calls++
+		return e
+	}
+
+	return nil
+}
+
+// MockExpectation is a request expected by a MockServer, and its response.
+type MockExpectation struct {
+	method   string
+	path     string
+	matchers []func(r *http.Request, body []byte) bool
+
+	status int
+	header http.Header
+	body   []byte
+	events []sse.ServerSentEvent
+
+	times int
+	calls int
+}
+
+func (e *MockExpectation) matches(r *http.Request, body []byte) bool {
+	if r.Method != e.method || r.URL.Path != e.path {
+		return false
+	}
+
+	for _, match := range e.matchers {
+		if !match(r, body) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WithQuery matches requests with the query param.
+func (e *MockExpectation) WithQuery(key, value string) *MockExpectation {
+	return e.WithMatch(func(r *http.Request, body []byte) bool {
+		return r.URL.Query().Get(key) == value
+	})
+}
+
+// WithHeader matches requests with the header.
+func (e *MockExpectation) WithHeader(key, value string) *MockExpectation {
+	return e.WithMatch(func(r *http.Request, body []byte) bool {
+		return r.Header.Get(key) == value
+	})
+}
+
+// WithJSONBody matches requests whose body is JSON equal to expected.
+func (e *MockExpectation) WithJSONBody(expected string) *MockExpectation {
+	return e.WithMatch(func(r *http.Request, body []byte) bool {
+		var a, b any
+		if json.Unmarshal([]byte(expected), &a) != nil || json.Unmarshal(body, &b) != nil {
+			return false
+		}
+
+		ja, _ := json.Marshal(a)
+		jb, _ := json.Marshal(b)
+		return bytes.Equal(ja, jb)
+	})
+}
+
+// WithMatch matches requests with a custom function.
+func (e *MockExpectation) WithMatch(match func(r *http.Request, body []byte) bool) *MockExpectation {
+	e.matchers = append(e.matchers, match)
+	return e
+}
+
+// Times sets the exact number of calls expected. By default, an expectation
+// matches any number of calls, but at least one.
+func (e *MockExpectation) Times(n int) *MockExpectation {
+	e.times = n
+	return e
+}
+
+// RespondHeader sets a response header.
+func (e *MockExpectation) RespondHeader(key, value string) *MockExpectation {
+	e.header.Set(key, value)
+	return e
+}
+
+// RespondJSON responds with a JSON body. body may be a string or []byte of
+// raw JSON, or a value to marshal.
+func (e *MockExpectation) RespondJSON(status int, body any) *MockExpectation {
+	e.status = status
+	e.header.Set("Content-Type", "application/json")
+
+	switch body := body.(type) {
+	case string:
+		e.body = []byte(body)
+	case []byte:
+		e.body = body
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			panic(fmt.Sprintf("fetch mock: marshal response: %v", err))
+		}
+		e.body = data
+	}
+
+	return e
+}
+
+// RespondSSE responds with a stream of server-sent events.
+func (e *MockExpectation) RespondSSE(events ...sse.ServerSentEvent) *MockExpectation {
+	e.status = http.StatusOK
+	e.header.Set("Content-Type", "text/event-stream")
+	e.events = events
+	return e
+}
+
+func (e *MockExpectation) respond(w http.ResponseWriter) {
+	for key, values := range e.header {
+		w.Header()[key] = values
+	}
+
+	w.WriteHeader(e.status)
+
+	if e.events == nil {
+		w.Write(e.body)
+		return
+	}
+
+	for _, ev := range e.events {
+		sse.WriteEvent(w, ev)
+	}
+}
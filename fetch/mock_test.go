@@ -0,0 +1,57 @@
+package fetch_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hayeah/goo/fetch"
+	"github.com/hayeah/goo/fetch/sse"
+)
+
+func TestMockServer(t *testing.T) {
+	assert := assert.New(t)
+
+	server := fetch.NewMockServer(t)
+
+	server.On("POST", "/users").
+		WithJSONBody(`{"name": "alice"}`).
+		RespondJSON(http.StatusCreated, map[string]any{"id": 1}).
+		Times(1)
+
+	server.On("GET", "/users").
+		WithQuery("page", "2").
+		RespondJSON(http.StatusOK, `{"users": []}`)
+
+	server.On("GET", "/events").
+		RespondSSE(
+			sse.ServerSentEvent{Event: "greeting", Data: "hello\nworld"},
+			sse.ServerSentEvent{ID: "2", Data: "bye"},
+		)
+
+	opts := server.Options()
+
+	res, err := opts.JSON("POST", "/users", &fetch.Options{Body: map[string]string{"name": "alice"}})
+	assert.NoError(err)
+	assert.Equal(http.StatusCreated, res.Response().StatusCode)
+	assert.Equal(int64(1), res.Get("id").Int())
+
+	res, err = opts.JSON("GET", "/users", &fetch.Options{QueryParams: map[string][]string{"page": {"2"}}})
+	assert.NoError(err)
+	assert.True(res.Get("users").IsArray())
+
+	stream, err := opts.SSE("GET", "/events", nil)
+	assert.NoError(err)
+	defer stream.Close()
+
+	var events []sse.ServerSentEvent
+	for stream.Next() {
+		events = append(events, stream.Event())
+	}
+
+	assert.Equal([]sse.ServerSentEvent{
+		{Event: "greeting", Data: "hello\nworld"},
+		{ID: "2", Data: "bye"},
+	}, events)
+}
@@ -0,0 +1,118 @@
+package fetch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/tidwall/gjson"
+)
+
+// NDJSONResponse iterates over a newline delimited JSON (JSON Lines) stream,
+// one JSON value per line.
+//
+//	res, err := fetch.NDJSON("GET", "/events", opts)
+//	if err != nil {
+//		return err
+//	}
+//	defer res.Close()
+//
+//	for res.Next() {
+//		var ev Event
+//		if err := res.Decode(&ev); err != nil {
+//			return err
+//		}
+//	}
+//	return res.Err()
+type NDJSONResponse struct {
+	response *http.Response
+	scanner  *bufio.Scanner
+
+	line []byte
+	err  error
+}
+
+// Response returns the original http.Response.
+func (r *NDJSONResponse) Response() *http.Response {
+	return r.response
+}
+
+// Next advances to the next non-empty line. It returns false at the end of the
+// stream, or on error.
+func (r *NDJSONResponse) Next() bool {
+	for r.scanner.Scan() {
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		r.line = line
+		return true
+	}
+
+	r.line = nil
+	r.err = r.scanner.Err()
+	return false
+}
+
+// Bytes returns the current line. The slice is only valid until the next call
+// to Next.
+func (r *NDJSONResponse) Bytes() []byte {
+	return r.line
+}
+
+// Decode unmarshals the current line into v.
+func (r *NDJSONResponse) Decode(v any) error {
+	return json.Unmarshal(r.line, v)
+}
+
+// Get queries json path of the current line using GJSON
+func (r *NDJSONResponse) Get(path string) GJSONResult {
+	if path == "" {
+		return GJSONResult{gjson.ParseBytes(r.line)}
+	}
+	return GJSONResult{gjson.GetBytes(r.line, path)}
+}
+
+// Err returns the first error encountered while reading the stream.
+func (r *NDJSONResponse) Err() error {
+	return r.err
+}
+
+// Close closes the response body.
+func (r *NDJSONResponse) Close() error {
+	return r.response.Body.Close()
+}
+
+// NDJSON creates a new request and returns the response as a stream of JSON
+// values. A response with status >= 400 is returned as a JSONError.
+func NDJSON(method, resource string, opts *Options) (*NDJSONResponse, error) {
+	res, err := opts.Do(method, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= 400 {
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		jres := &JSONResponse{response: res, body: body}
+		return nil, &JSONError{JSONResponse: jres}
+	}
+
+	scanner := bufio.NewScanner(res.Body)
+	if opts.MaxLineSize > 0 {
+		scanner.Buffer(make([]byte, 0, min(opts.MaxLineSize, bufio.MaxScanTokenSize)), opts.MaxLineSize)
+	}
+
+	return &NDJSONResponse{
+		response: res,
+		scanner:  scanner,
+	}, nil
+}
@@ -0,0 +1,155 @@
+// Package oauth2 provides OAuth2 token sources for the fetch package.
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hayeah/goo/fetch"
+)
+
+const defaultExpiryDelta = 10 * time.Second
+
+// ClientCredentials is a fetch.TokenSource that requests tokens with the OAuth2
+// client credentials grant. Tokens are cached, and refreshed shortly before
+// they expire.
+//
+//	api := &fetch.Options{
+//		BaseURL: "https://api.example.com",
+//		TokenSource: &oauth2.ClientCredentials{
+//			TokenURL:     "https://auth.example.com/oauth/token",
+//			ClientID:     cfg.ClientID,
+//			ClientSecret: cfg.ClientSecret,
+//		},
+//	}
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	// EndpointParams are additional form parameters sent to the token endpoint,
+	// e.g. "audience".
+	EndpointParams url.Values
+
+	// AuthInParams sends the client credentials as form parameters instead of
+	// HTTP basic auth, for providers that don't support the latter.
+	AuthInParams bool
+
+	// ExpiryDelta refreshes the token this long before it expires. Defaults to
+	// 10 seconds.
+	ExpiryDelta time.Duration
+
+	// Options are the fetch options used to request tokens, e.g. to set a
+	// Client or Logger.
+	Options *fetch.Options
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time // zero if the token doesn't expire
+}
+
+var _ fetch.TokenSource = (*ClientCredentials)(nil)
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Token returns a cached token, or requests a new one if it is about to expire.
+func (c *ClientCredentials) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.valid() {
+		return c.token, nil
+	}
+
+	token, expiry, err := c.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	c.token = token
+	c.expiry = expiry
+
+	return c.token, nil
+}
+
+// valid reports whether the cached token can still be used.
+func (c *ClientCredentials) valid() bool {
+	if c.token == "" {
+		return false
+	}
+
+	if c.expiry.IsZero() {
+		return true
+	}
+
+	delta := c.ExpiryDelta
+	if delta == 0 {
+		delta = defaultExpiryDelta
+	}
+
+	return time.Now().Add(delta).Before(c.expiry)
+}
+
+func (c *ClientCredentials) requestToken(ctx context.Context) (string, time.Time, error) {
+	form := url.Values{}
+	for key, values := range c.EndpointParams {
+		form[key] = values
+	}
+
+	form.Set("grant_type", "client_credentials")
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+
+	opts := &fetch.Options{
+		Context: ctx,
+		Header:  http.Header{},
+	}
+
+	opts.SetHeader("Content-Type", "application/x-www-form-urlencoded")
+	opts.SetHeader("Accept", "application/json")
+
+	if c.AuthInParams {
+		form.Set("client_id", c.ClientID)
+		form.Set("client_secret", c.ClientSecret)
+	} else {
+		opts.BasicAuth = &fetch.BasicAuth{
+			Username: url.QueryEscape(c.ClientID),
+			Password: url.QueryEscape(c.ClientSecret),
+		}
+	}
+
+	opts.Body = form.Encode()
+
+	if c.Options != nil {
+		opts = c.Options.Merge(opts)
+		// TokenURL is absolute
+		opts.BaseURL = ""
+	}
+
+	res, _, err := fetch.JSONAs[tokenResponse](http.MethodPost, c.TokenURL, opts)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if res.AccessToken == "" {
+		return "", time.Time{}, errors.New("oauth2: server response missing access_token")
+	}
+
+	var expiry time.Time
+	if res.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+	}
+
+	return res.AccessToken, expiry, nil
+}
@@ -0,0 +1,62 @@
+package oauth2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hayeah/goo/fetch"
+)
+
+func TestClientCredentials(t *testing.T) {
+	assert := assert.New(t)
+
+	var tokenRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+
+			user, pass, ok := r.BasicAuth()
+			assert.True(ok)
+			assert.Equal("client", user)
+			assert.Equal("secret", pass)
+
+			assert.NoError(r.ParseForm())
+			assert.Equal("client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal("read write", r.PostForm.Get("scope"))
+
+			w.Write([]byte(`{"access_token": "abc", "token_type": "bearer", "expires_in": 3600}`))
+		case "/api":
+			w.Write([]byte(`{"auth": "` + r.Header.Get("Authorization") + `"}`))
+		}
+	}))
+	defer server.Close()
+
+	source := &ClientCredentials{
+		TokenURL:     server.URL + "/token",
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+	}
+
+	opts := &fetch.Options{BaseURL: server.URL, TokenSource: source}
+
+	for i := 0; i < 2; i++ {
+		res, err := opts.JSON(http.MethodGet, "/api", nil)
+		assert.NoError(err)
+		assert.Equal("Bearer abc", res.Get("auth").String())
+	}
+
+	assert.Equal(1, tokenRequests)
+
+	// force a refresh
+	source.ExpiryDelta = 2 * time.Hour
+	_, err := source.Token(context.Background())
+	assert.NoError(err)
+	assert.Equal(2, tokenRequests)
+}
@@ -0,0 +1,55 @@
+// Package otelfetch traces fetch requests with OpenTelemetry.
+package otelfetch
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hayeah/goo/fetch"
+)
+
+// Interceptor starts a client span for every request, and propagates the
+// trace to the server in the request headers. A nil tp uses the global tracer
+// provider.
+func Interceptor(tp trace.TracerProvider) fetch.Interceptor {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	tracer := tp.Tracer("github.com/hayeah/goo/fetch")
+
+	return func(next fetch.RoundTripFunc) fetch.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx, span := tracer.Start(req.Context(), req.Method+" "+req.URL.Host,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("http.request.method", req.Method),
+					attribute.String("url.full", req.URL.Redacted()),
+				),
+			)
+			defer span.End()
+
+			req = req.Clone(ctx)
+			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			res, err := next(req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return nil, err
+			}
+
+			span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+			if res.StatusCode >= 400 {
+				span.SetStatus(codes.Error, res.Status)
+			}
+
+			return res, nil
+		}
+	}
+}
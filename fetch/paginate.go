@@ -0,0 +1,197 @@
+package fetch
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageRequest is the resource and options used to request a page.
+type PageRequest struct {
+	Resource string
+	Options  *Options
+}
+
+// Query returns the query params of the request.
+func (r *PageRequest) Query() url.Values {
+	// the params were rendered successfully for the previous request
+	query, _ := RenderQuery(r.Options.QueryParams)
+	return query
+}
+
+// clone copies the request, with the query params rendered as url.Values that
+// can be modified.
+func (r *PageRequest) clone() (*PageRequest, url.Values) {
+	query := r.Query()
+
+	opts := *r.Options
+	opts.QueryParams = query
+
+	return &PageRequest{Resource: r.Resource, Options: &opts}, query
+}
+
+// NextPageFunc returns the request for the page after res, or false if res is
+// the last page.
+type NextPageFunc func(res *JSONResponse, prev *PageRequest) (*PageRequest, bool)
+
+// LinkNext follows the rel="next" URL of the Link response header (RFC 8288),
+// as used by GitHub and others.
+func LinkNext() NextPageFunc {
+	return func(res *JSONResponse, prev *PageRequest) (*PageRequest, bool) {
+		link := parseLinkNext(res.Response().Header.Values("Link"))
+		if link == "" {
+			return nil, false
+		}
+
+		next, err := res.Response().Request.URL.Parse(link)
+		if err != nil {
+			return nil, false
+		}
+
+		req, _ := prev.clone()
+		req.Resource = next.String()
+		// the link is a complete URL
+		req.Options.BaseURL = ""
+		req.Options.PathParams = nil
+		req.Options.QueryParams = nil
+
+		return req, true
+	}
+}
+
+// parseLinkNext returns the URL with rel="next" in Link header values.
+func parseLinkNext(values []string) string {
+	for _, value := range values {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+
+			for _, param := range parts[1:] {
+				key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if strings.TrimSpace(key) != "rel" {
+					continue
+				}
+
+				for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+					if rel == "next" {
+						return strings.Trim(target, "<>")
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// Cursor sets the query param to the cursor found at the GJSON path of the
+// response body. Pagination stops when the cursor is missing or empty.
+func Cursor(path, param string) NextPageFunc {
+	return func(res *JSONResponse, prev *PageRequest) (*PageRequest, bool) {
+		cursor := res.Get(path).String()
+		if cursor == "" {
+			return nil, false
+		}
+
+		req, query := prev.clone()
+		query.Set(param, cursor)
+		return req, true
+	}
+}
+
+// PageNumber increments the page number query param, starting from the value
+// of the param in the first request (or 1 if it is not set). Pagination stops
+// when the items array at the GJSON path is empty.
+func PageNumber(param, itemsPath string) NextPageFunc {
+	return func(res *JSONResponse, prev *PageRequest) (*PageRequest, bool) {
+		if len(res.Get(itemsPath).Array()) == 0 {
+			return nil, false
+		}
+
+		page := 1
+		if v := prev.Query().Get(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, false
+			}
+			page = n
+		}
+
+		req, query := prev.clone()
+		query.Set(param, strconv.Itoa(page+1))
+		return req, true
+	}
+}
+
+// Pager iterates over the pages of a paginated JSON API.
+//
+//	pager := fetch.Paginate("GET", "/items", opts, fetch.Cursor("next_cursor", "cursor"))
+//	for pager.Next() {
+//		page := pager.Page()
+//	}
+//	if err := pager.Err(); err != nil {
+//		return err
+//	}
+type Pager struct {
+	method string
+	req    *PageRequest
+	next   NextPageFunc
+
+	page *JSONResponse
+	err  error
+	done bool
+}
+
+// Paginate returns a Pager that requests pages until next returns false.
+func Paginate(method, resource string, opts *Options, next NextPageFunc) *Pager {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	return &Pager{
+		method: method,
+		req:    &PageRequest{Resource: resource, Options: opts},
+		next:   next,
+	}
+}
+
+// Next requests the next page. It returns false after the last page, or if a
+// request fails.
+func (p *Pager) Next() bool {
+	if p.done {
+		return false
+	}
+
+	if p.page != nil {
+		req, ok := p.next(p.page, p.req)
+		if !ok {
+			p.done = true
+			return false
+		}
+		p.req = req
+	}
+
+	page, err := JSON(p.method, p.req.Resource, p.req.Options)
+	if err != nil {
+		p.err = err
+		p.done = true
+		return false
+	}
+
+	p.page = page
+	return true
+}
+
+// Page returns the current page.
+func (p *Pager) Page() *JSONResponse {
+	return p.page
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (p *Pager) Err() error {
+	return p.err
+}
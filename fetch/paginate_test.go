@@ -0,0 +1,71 @@
+package fetch
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLinkNext(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("https://api.example.com/items?page=2", parseLinkNext([]string{
+		`<https://api.example.com/items?page=2>; rel="next", <https://api.example.com/items?page=5>; rel="last"`,
+	}))
+	assert.Equal("/items?page=3", parseLinkNext([]string{`</items?page=1>; rel="prev"`, `</items?page=3>; rel="next"`}))
+	assert.Equal("", parseLinkNext([]string{`</items?page=1>; rel="prev"`}))
+	assert.Equal("", parseLinkNext(nil))
+}
+
+func TestPaginate(t *testing.T) {
+	assert := assert.New(t)
+
+	const lastPage = 3
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		if page < lastPage {
+			w.Header().Set("Link", fmt.Sprintf(`</items?page=%d>; rel="next"`, page+1))
+		}
+
+		var items string
+		if page <= lastPage {
+			items = fmt.Sprintf(`[%d]`, page)
+		} else {
+			items = `[]`
+		}
+
+		cursor := ""
+		if page < lastPage {
+			cursor = strconv.Itoa(page + 1)
+		}
+
+		fmt.Fprintf(w, `{"items": %s, "next": %q}`, items, cursor)
+	}))
+	defer server.Close()
+
+	collect := func(pager *Pager) []int64 {
+		var items []int64
+		for pager.Next() {
+			for _, item := range pager.Page().Get("items").Array() {
+				items = append(items, item.Int())
+			}
+		}
+		assert.NoError(pager.Err())
+		return items
+	}
+
+	opts := &Options{BaseURL: server.URL}
+
+	assert.Equal([]int64{1, 2, 3}, collect(Paginate(http.MethodGet, "/items", opts, LinkNext())))
+	assert.Equal([]int64{1, 2, 3}, collect(Paginate(http.MethodGet, "/items", opts, Cursor("next", "page"))))
+	assert.Equal([]int64{1, 2, 3}, collect(Paginate(http.MethodGet, "/items", opts, PageNumber("page", "items"))))
+}
@@ -0,0 +1,111 @@
+package fetch
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter limits the rate of requests, either across all hosts or per host.
+// It is safe for concurrent use, and should be shared by the Options of a
+// client.
+type RateLimiter struct {
+	limit   rate.Limit
+	burst   int
+	perHost bool
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// NewRateLimiter allows rps requests per second with bursts of up to burst
+// requests, across all hosts.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	return &RateLimiter{limit: rate.Limit(rps), burst: burst}
+}
+
+// NewHostRateLimiter allows rps requests per second with bursts of up to burst
+// requests, to each host.
+func NewHostRateLimiter(rps float64, burst int) *RateLimiter {
+	return &RateLimiter{limit: rate.Limit(rps), burst: burst, perHost: true}
+}
+
+func (l *RateLimiter) limiter(host string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.perHost {
+		host = ""
+	}
+
+	if l.limiters == nil {
+		l.limiters = map[string]*rate.Limiter{}
+	}
+
+	limiter, ok := l.limiters[host]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[host] = limiter
+	}
+
+	return limiter
+}
+
+func (l *RateLimiter) interceptor() Interceptor {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			err := l.limiter(req.URL.Host).Wait(req.Context())
+			if err != nil {
+				return nil, err
+			}
+
+			return next(req)
+		}
+	}
+}
+
+// RateLimitStatus is the rate limit reported by the X-RateLimit-* response
+// headers.
+type RateLimitStatus struct {
+	// Limit is the number of requests allowed in the window, or -1 if not
+	// reported.
+	Limit int
+	// Remaining is the number of requests left in the window.
+	Remaining int
+	// Reset is when the window resets, or zero if not reported.
+	Reset time.Time
+}
+
+// resetEpochThreshold separates X-RateLimit-Reset values that are unix
+// timestamps from values that are seconds until the reset.
+const resetEpochThreshold = 1_000_000_000
+
+// ParseRateLimit parses the X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset headers. X-RateLimit-Reset may be a unix timestamp or the
+// seconds until the reset. It returns false if X-RateLimit-Remaining is
+// missing or invalid.
+func ParseRateLimit(header http.Header) (RateLimitStatus, bool) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimitStatus{}, false
+	}
+
+	status := RateLimitStatus{Limit: -1, Remaining: remaining}
+
+	if limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		status.Limit = limit
+	}
+
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		if reset >= resetEpochThreshold {
+			status.Reset = time.Unix(reset, 0)
+		} else {
+			status.Reset = time.Now().Add(time.Duration(reset) * time.Second)
+		}
+	}
+
+	return status, true
+}
@@ -0,0 +1,90 @@
+package fetch
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// sensitiveHeaders are always redacted from logs, curl commands and HAR files.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+const redacted = "[REDACTED]"
+
+// Redact lists secrets to mask in debug output (logged bodies, curl commands
+// and HAR files), in addition to the sensitive headers that are always
+// redacted.
+type Redact struct {
+	// Headers are additional header names to redact.
+	Headers []string
+	// BodyPaths are GJSON paths of JSON body values to redact, e.g.
+	// "password" or "credentials.secret".
+	BodyPaths []string
+}
+
+// header reports whether the value of the header should be redacted. A nil
+// Redact redacts the sensitive headers.
+func (r *Redact) header(key string) bool {
+	for _, h := range sensitiveHeaders {
+		if strings.EqualFold(h, key) {
+			return true
+		}
+	}
+
+	if r == nil {
+		return false
+	}
+
+	for _, h := range r.Headers {
+		if strings.EqualFold(h, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Header returns a copy of header with redacted values.
+func (r *Redact) Header(header http.Header) http.Header {
+	out := make(http.Header, len(header))
+	for key, values := range header {
+		if r.header(key) {
+			values = []string{redacted}
+		}
+		out[key] = values
+	}
+
+	return out
+}
+
+// Body returns a copy of the JSON body with the values at BodyPaths redacted.
+// Non-JSON bodies are returned as is.
+func (r *Redact) Body(body []byte) []byte {
+	if r == nil || len(r.BodyPaths) == 0 || !gjson.ValidBytes(body) {
+		return body
+	}
+
+	out := body
+	for _, path := range r.BodyPaths {
+		if !gjson.GetBytes(out, path).Exists() {
+			continue
+		}
+
+		redactedBody, err := sjson.SetBytes(out, path, redacted)
+		if err != nil {
+			continue
+		}
+		out = redactedBody
+	}
+
+	return out
+}
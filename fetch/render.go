@@ -2,15 +2,41 @@ package fetch
 
 import (
 	"bytes"
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hayeah/mustache/v2"
 )
 
-// RenderJSON renders a mustache URL template with the given data.
+// RenderURLPath renders a mustache URL template with the given data. Values
+// are escaped as path segments, so "a b/c" renders as "a%20b%2Fc". Use triple
+// mustaches, {{{Path}}} or {{&Path}}, to render a value as is.
 func RenderURLPath(path string, data interface{}) (string, error) {
-	// FIXME: should escape URL...
-	// url.PathEscape(path)
-	template, err := mustache.New().WithEscapeMode(mustache.Raw).CompileString(path)
+	return renderURLPath(path, data, true)
+}
+
+// RenderRawURLPath renders a mustache URL template with the given data,
+// without escaping values.
+func RenderRawURLPath(path string, data interface{}) (string, error) {
+	return renderURLPath(path, data, false)
+}
+
+func pathEscapeValue(v any) (string, error) {
+	return url.PathEscape(fmt.Sprint(v)), nil
+}
+
+func renderURLPath(path string, data interface{}, escape bool) (string, error) {
+	compiler := mustache.New().WithEscapeMode(mustache.Raw)
+	if escape {
+		compiler = compiler.WithValueStringer(pathEscapeValue)
+	}
+
+	template, err := compiler.CompileString(path)
 	if err != nil {
 		return "", err
 	}
@@ -24,3 +50,203 @@ func RenderURLPath(path string, data interface{}) (string, error) {
 
 	return buf.String(), nil
 }
+
+// RenderQueryTemplates renders mustache templates in query values with the
+// data, e.g. {"since": {"{{Since}}"}}. Values without templates are kept as is.
+func RenderQueryTemplates(query url.Values, data ...any) error {
+	var contexts []any
+	for _, d := range data {
+		if d != nil {
+			contexts = append(contexts, d)
+		}
+	}
+
+	if len(contexts) == 0 {
+		return nil
+	}
+
+	for key, values := range query {
+		for i, value := range values {
+			if !strings.Contains(value, "{{") {
+				continue
+			}
+
+			template, err := mustache.New().WithEscapeMode(mustache.Raw).CompileString(value)
+			if err != nil {
+				return fmt.Errorf("render query %s: %w", key, err)
+			}
+
+			rendered, err := template.Render(contexts...)
+			if err != nil {
+				return fmt.Errorf("render query %s: %w", key, err)
+			}
+
+			values[i] = rendered
+		}
+	}
+
+	return nil
+}
+
+// RenderQuery renders query params into url.Values. params may be url.Values,
+// a map with string, slice or scalar values, or a struct.
+//
+// Struct fields are named by the `query` tag, or the field name. The
+// "omitempty" tag option omits zero values, and "-" skips the field. Slices
+// are rendered as repeated params. time.Time is formatted as RFC3339, or with
+// the layout in the `layout` tag ("unix" for unix seconds).
+//
+//	type ListParams struct {
+//		Tags  []string  `query:"tag"`
+//		Since time.Time `query:"since,omitempty" layout:"2006-01-02"`
+//		Limit int       `query:"limit,omitempty"`
+//	}
+func RenderQuery(params any) (url.Values, error) {
+	values := url.Values{}
+
+	switch params := params.(type) {
+	case nil:
+		return values, nil
+	case url.Values:
+		for key, vs := range params {
+			values[key] = append([]string{}, vs...)
+		}
+		return values, nil
+	case map[string][]string:
+		return RenderQuery(url.Values(params))
+	}
+
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return values, nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("render query: map key must be string, got %s", v.Type().Key())
+		}
+
+		iter := v.MapRange()
+		for iter.Next() {
+			err := addQueryValue(values, iter.Key().String(), iter.Value(), "")
+			if err != nil {
+				return nil, err
+			}
+		}
+	case reflect.Struct:
+		err := addQueryStruct(values, v)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("render query: unsupported type %T", params)
+	}
+
+	return values, nil
+}
+
+func addQueryStruct(values url.Values, v reflect.Value) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("query")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		omitempty := opts == "omitempty"
+
+		fv := v.Field(i)
+
+		if field.Anonymous && name == "" && fv.Kind() == reflect.Struct {
+			err := addQueryStruct(values, fv)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if omitempty && fv.IsZero() {
+			continue
+		}
+
+		err := addQueryValue(values, name, fv, field.Tag.Get("layout"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func addQueryValue(values url.Values, key string, v reflect.Value, layout string) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 || v.Kind() == reflect.Array {
+		for i := 0; i < v.Len(); i++ {
+			err := addQueryValue(values, key, v.Index(i), layout)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		switch layout {
+		case "":
+			values.Add(key, t.Format(time.RFC3339))
+		case "unix":
+			values.Add(key, strconv.FormatInt(t.Unix(), 10))
+		default:
+			values.Add(key, t.Format(layout))
+		}
+		return nil
+	}
+
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		if err != nil {
+			return fmt.Errorf("render query %s: %w", key, err)
+		}
+		values.Add(key, string(text))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		values.Add(key, fmt.Sprint(v.Interface()))
+	case reflect.Slice:
+		// []byte
+		values.Add(key, string(v.Bytes()))
+	default:
+		return fmt.Errorf("render query %s: unsupported type %s", key, v.Type())
+	}
+
+	return nil
+}
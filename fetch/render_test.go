@@ -1,7 +1,9 @@
 package fetch
 
 import (
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -44,6 +46,17 @@ func TestRenderURLPath(t *testing.T) {
 			data:     Params{UserID: "987", BookID: "cba"},
 			expected: "/user/987/book/cba/detail",
 		},
+
+		{
+			path:     "/files/{{Name}}",
+			data:     map[string]interface{}{"Name": "a b/c?d"},
+			expected: "/files/a%20b%2Fc%3Fd",
+		},
+		{
+			path:     "/files/{{{Name}}}/{{&Name}}",
+			data:     map[string]interface{}{"Name": "a/b"},
+			expected: "/files/a/b/a/b",
+		},
 	}
 
 	for _, tt := range tests {
@@ -51,4 +64,68 @@ func TestRenderURLPath(t *testing.T) {
 		assert.NoError(err)
 		assert.Equal(tt.expected, result)
 	}
+
+	result, err := RenderRawURLPath("/files/{{Name}}", map[string]interface{}{"Name": "a/b"})
+	assert.NoError(err)
+	assert.Equal("/files/a/b", result)
+}
+
+func TestRenderQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	type Paging struct {
+		Limit  int `query:"limit,omitempty"`
+		Offset int `query:"offset,omitempty"`
+	}
+
+	type Params struct {
+		Paging
+		Tags    []string  `query:"tag"`
+		Since   time.Time `query:"since,omitempty" layout:"2006-01-02"`
+		Until   time.Time `query:"until,omitempty" layout:"unix"`
+		At      time.Time `query:"at,omitempty"`
+		Cursor  *string   `query:"cursor"`
+		Deleted bool      `query:"deleted"`
+		Secret  string    `query:"-"`
+		Name    string
+	}
+
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		params   any
+		expected string
+	}{
+		{"nil", nil, ""},
+		{"url.Values", url.Values{"a": {"1", "2"}}, "a=1&a=2"},
+		{"map of slices", map[string][]string{"a": {"1"}}, "a=1"},
+		{"map of strings", map[string]string{"a": "1", "b": "x y"}, "a=1&b=x+y"},
+		{"map of any", map[string]any{"n": 1, "ids": []int{1, 2}, "nil": nil}, "ids=1&ids=2&n=1"},
+		{
+			"struct",
+			Params{
+				Paging: Paging{Limit: 10},
+				Tags:   []string{"a", "b"},
+				Since:  since,
+				Until:  since,
+				At:     since,
+				Secret: "secret",
+				Name:   "alice",
+			},
+			"Name=alice&at=2024-01-02T03%3A04%3A05Z&deleted=false&limit=10&since=2024-01-02&tag=a&tag=b&until=1704164645",
+		},
+		{"pointer to struct", &Paging{Offset: 5}, "offset=5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := RenderQuery(tt.params)
+			assert.NoError(err)
+			assert.Equal(tt.expected, values.Encode())
+		})
+	}
+
+	_, err := RenderQuery(42)
+	assert.Error(err)
 }
@@ -0,0 +1,132 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Resolver resolves host names to IP addresses for the transport.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+const defaultResolverTTL = time.Minute
+
+// CachingResolver caches the addresses resolved by Resolver for a fixed TTL,
+// so high rate requests to the same hosts don't hit DNS every time. The TTLs
+// of the DNS records are not known to it, and are ignored: choose a TTL no
+// longer than theirs, so changed records are picked up in time.
+type CachingResolver struct {
+	// Resolver is the upstream resolver. Defaults to net.DefaultResolver.
+	Resolver Resolver
+	// TTL is how long resolved addresses are cached, regardless of the TTLs of
+	// the DNS records. Defaults to 1 minute.
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]resolverEntry
+}
+
+type resolverEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// NewCachingResolver creates a CachingResolver over net.DefaultResolver, that
+// caches addresses for ttl.
+func NewCachingResolver(ttl time.Duration) *CachingResolver {
+	return &CachingResolver{TTL: ttl}
+}
+
+func (r *CachingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	entry, ok := r.entries[host]
+	r.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	var upstream Resolver = net.DefaultResolver
+	if r.Resolver != nil {
+		upstream = r.Resolver
+	}
+
+	addrs, err := upstream.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := r.TTL
+	if ttl <= 0 {
+		ttl = defaultResolverTTL
+	}
+
+	r.mu.Lock()
+	if r.entries == nil {
+		r.entries = map[string]resolverEntry{}
+	}
+	r.entries[host] = resolverEntry{addrs: addrs, expires: time.Now().Add(ttl)}
+	r.mu.Unlock()
+
+	return addrs, nil
+}
+
+// StaticResolver pins hosts to IP addresses, e.g. for testing. Hosts not in
+// the map are resolved with Fallback, or fail if it is nil.
+type StaticResolver struct {
+	Hosts    map[string][]string
+	Fallback Resolver
+}
+
+func (r *StaticResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addrs, ok := r.Hosts[host]; ok {
+		return addrs, nil
+	}
+
+	if r.Fallback != nil {
+		return r.Fallback.LookupHost(ctx, host)
+	}
+
+	return nil, &net.DNSError{Err: "host not pinned", Name: host, IsNotFound: true}
+}
+
+// resolvingDialer returns a DialContext that resolves hosts with the resolver,
+// and dials the addresses in order until one connects.
+func resolvingDialer(resolver Resolver) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	var dialer net.Dialer
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		if net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		addrs, err := resolver.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(addrs) == 0 {
+			return nil, &net.DNSError{Err: "no addresses", Name: host, IsNotFound: true}
+		}
+
+		var errs []error
+		for _, ip := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+
+		return nil, errors.Join(errs...)
+	}
+}
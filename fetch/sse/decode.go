@@ -0,0 +1,76 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DecodeData unmarshals the JSON data of the event into a T.
+func DecodeData[T any](ev ServerSentEvent) (T, error) {
+	var v T
+	err := json.Unmarshal([]byte(ev.Data), &v)
+	if err != nil {
+		return v, fmt.Errorf("sse: decode event %q (id %q): %w", ev.Event, ev.ID, err)
+	}
+
+	return v, nil
+}
+
+// EventIter iterates the events of a scanner, decoding their JSON data into T.
+type EventIter[T any] struct {
+	scanner *Scanner
+
+	event ServerSentEvent
+	value T
+	errs  []error
+}
+
+// Events returns an iterator decoding the JSON data of each event into T:
+//
+//	events := sse.Events[Message](scanner)
+//	for events.Next() {
+//		msg := events.Value()
+//	}
+//	err := events.Err()
+//
+// Events that fail to decode are skipped, and their errors are reported by
+// Err, with the error of the scanner.
+func Events[T any](scanner *Scanner) *EventIter[T] {
+	return &EventIter[T]{scanner: scanner}
+}
+
+// Next advances to the next event that decodes.
+func (it *EventIter[T]) Next() bool {
+	for it.scanner.Next() {
+		ev := it.scanner.Event()
+
+		v, err := DecodeData[T](ev)
+		if err != nil {
+			it.errs = append(it.errs, err)
+			continue
+		}
+
+		it.event = ev
+		it.value = v
+		return true
+	}
+
+	return false
+}
+
+// Value returns the decoded data of the current event.
+func (it *EventIter[T]) Value() T {
+	return it.value
+}
+
+// Event returns the current event.
+func (it *EventIter[T]) Event() ServerSentEvent {
+	return it.event
+}
+
+// Err returns the decode errors of the skipped events, and the error of the
+// scanner.
+func (it *EventIter[T]) Err() error {
+	return errors.Join(append(it.errs, it.scanner.Err())...)
+}
@@ -0,0 +1,126 @@
+package sse
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// recordedChunk is a line of a recording: a read from the stream, and its
+// time since the start of the recording.
+type recordedChunk struct {
+	Offset time.Duration `json:"offset"`
+	Data   string        `json:"data"`
+}
+
+// Record writes the raw bytes read from the stream to w, with their timing,
+// as JSON lines. Replay reads the recording back. Like Tee, it must be called
+// before the first call to Next.
+func (s *Scanner) Record(w io.Writer) {
+	type readCloser struct {
+		io.Reader
+		io.Closer
+	}
+
+	s.readCloser = &readCloser{
+		Reader: &recordingReader{r: s.readCloser, enc: json.NewEncoder(w), start: time.Now()},
+		Closer: s.readCloser,
+	}
+
+	s.setReader(s.readCloser)
+}
+
+type recordingReader struct {
+	r     io.Reader
+	enc   *json.Encoder
+	start time.Time
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		chunk := recordedChunk{Offset: time.Since(r.start), Data: string(p[:n])}
+		if encErr := r.enc.Encode(chunk); encErr != nil {
+			return n, fmt.Errorf("sse: record: %w", encErr)
+		}
+	}
+
+	return n, err
+}
+
+// Replay returns a scanner reading a recording made with Record. The reads are
+// replayed with their original timing divided by speed, e.g. 2 replays twice
+// as fast. A speed <= 0 replays without delays.
+func Replay(r io.Reader, speed float64) *Scanner {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(replay(r, pw, speed))
+	}()
+
+	return NewScanner(pr, false)
+}
+
+// ReplayFile replays the recording in the file. See Replay.
+func ReplayFile(path string, speed float64) (*Scanner, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := Replay(f, speed)
+
+	type readCloser struct {
+		io.Reader
+		io.Closer
+	}
+
+	// close the file with the scanner
+	closer := s.readCloser
+	s.readCloser = &readCloser{
+		Reader: closer,
+		Closer: closerFunc(func() error {
+			closer.Close()
+			return f.Close()
+		}),
+	}
+
+	return s, nil
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func replay(r io.Reader, w io.Writer, speed float64) error {
+	start := time.Now()
+
+	scanner := bufio.NewScanner(r)
+	// a chunk may be as large as the reader buffer of the recorded stream
+	scanner.Buffer(nil, 16*1024*1024)
+
+	for scanner.Scan() {
+		var chunk recordedChunk
+		err := json.Unmarshal(scanner.Bytes(), &chunk)
+		if err != nil {
+			return fmt.Errorf("sse: replay: %w", err)
+		}
+
+		if speed > 0 {
+			at := time.Duration(float64(chunk.Offset) / speed)
+			time.Sleep(time.Until(start.Add(at)))
+		}
+
+		_, err = io.WriteString(w, chunk.Data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
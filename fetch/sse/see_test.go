@@ -2,10 +2,16 @@ package sse
 
 import (
 	"bufio"
+	"errors"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 // ChunksReader simulates a reader that splits the input across multiple reads.
@@ -270,3 +276,211 @@ func runSSEScanTest(t *testing.T, raw string, want []ServerSentEvent) {
 		t.Errorf("SSEScanner() = %v, want %v", got, want)
 	}
 }
+
+func TestDecodeData(t *testing.T) {
+	type message struct {
+		Text string `json:"text"`
+	}
+
+	got, err := DecodeData[message](ServerSentEvent{Data: `{"text": "hello"}`})
+	if err != nil {
+		t.Fatalf("DecodeData error: %v", err)
+	}
+
+	if got.Text != "hello" {
+		t.Errorf("DecodeData() = %v, want hello", got)
+	}
+
+	_, err = DecodeData[message](ServerSentEvent{ID: "2", Data: "not json"})
+	if err == nil || !strings.Contains(err.Error(), `id "2"`) {
+		t.Errorf("DecodeData() error = %v, want decode error of id 2", err)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	type message struct {
+		N int `json:"n"`
+	}
+
+	raw := "id: 1\ndata: {\"n\": 1}\n\nid: 2\ndata: oops\n\nid: 3\ndata: {\"n\": 3}\n\n"
+	events := Events[message](NewScanner(strings.NewReader(raw), false))
+
+	var got []int
+	var ids []string
+	for events.Next() {
+		got = append(got, events.Value().N)
+		ids = append(ids, events.Event().ID)
+	}
+
+	if !reflect.DeepEqual(got, []int{1, 3}) || !reflect.DeepEqual(ids, []string{"1", "3"}) {
+		t.Errorf("Events() = %v %v, want [1 3] [1 3]", got, ids)
+	}
+
+	err := events.Err()
+	if err == nil || !strings.Contains(err.Error(), `id "2"`) {
+		t.Errorf("Events() error = %v, want decode error of id 2", err)
+	}
+}
+
+func TestScannerMaxLineSize(t *testing.T) {
+	data := strings.Repeat("x", 100*1024)
+	raw := "data: " + data + "\n\n"
+
+	scanner := NewScanner(strings.NewReader(raw), false)
+	if scanner.Next() {
+		t.Errorf("Next() = true, want false for a line over the default limit")
+	}
+
+	err := scanner.Err()
+	if !errors.Is(err, bufio.ErrTooLong) || !strings.Contains(err.Error(), "max line size of 65536 bytes") {
+		t.Errorf("Err() = %v, want max line size error", err)
+	}
+
+	scanner = NewScanner(strings.NewReader(raw), false)
+	scanner.SetMaxLineSize(1024 * 1024)
+	if !scanner.Next() {
+		t.Fatalf("Next() = false, err %v", scanner.Err())
+	}
+
+	if scanner.Event().Data != data {
+		t.Errorf("Event().Data has length %d, want %d", len(scanner.Event().Data), len(data))
+	}
+}
+
+func TestWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	w, err := NewWriter(rec)
+	if err != nil {
+		t.Fatalf("NewWriter error: %v", err)
+	}
+
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", got)
+	}
+
+	w.Comment("hello")
+	w.Send(ServerSentEvent{ID: "1", Event: "update", Data: "line1\nline2"})
+	w.Send(ServerSentEvent{Data: "plain", Retry: 1000})
+
+	want := ":hello\n\nid: 1\nevent: update\ndata: line1\ndata: line2\n\nretry: 1000\ndata: plain\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+
+	if !rec.Flushed {
+		t.Errorf("Flushed = false, want true")
+	}
+
+	runSSEScanTest(t, strings.TrimPrefix(rec.Body.String(), ":hello\n\n"), []ServerSentEvent{
+		{ID: "1", Event: "update", Data: "line1\nline2"},
+		{Data: "plain", Retry: 1000},
+	})
+}
+
+type noFlushWriter struct {
+	http.ResponseWriter
+}
+
+func TestWriterRequiresFlusher(t *testing.T) {
+	_, err := NewWriter(noFlushWriter{httptest.NewRecorder()})
+	if err == nil {
+		t.Errorf("NewWriter error = nil, want error for a writer without flushing")
+	}
+}
+
+func TestWriterKeepAlive(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w, _ := NewWriter(rec)
+
+	stop := w.KeepAlive(10 * time.Millisecond)
+	time.Sleep(35 * time.Millisecond)
+	stop()
+	stop()
+
+	w.mu.Lock()
+	body := rec.Body.String()
+	w.mu.Unlock()
+
+	if !strings.HasPrefix(body, ":keepalive\n\n") {
+		t.Errorf("body = %q, want keepalive comments", body)
+	}
+}
+
+// slowReader delays each read after the first.
+type slowReader struct {
+	io.Reader
+	delay time.Duration
+	reads int
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if r.reads > 0 {
+		time.Sleep(r.delay)
+	}
+	r.reads++
+	return r.Reader.Read(p)
+}
+
+func TestRecordReplay(t *testing.T) {
+	chunks := []string{"id: 1\ndata: first\n\n", "id: 2\ndata: sec", "ond\n\n"}
+	want := []ServerSentEvent{{ID: "1", Data: "first"}, {ID: "2", Data: "second"}}
+
+	var recording strings.Builder
+	scanner := NewScanner(&slowReader{Reader: NewChunksReader(chunks), delay: 20 * time.Millisecond}, false)
+	scanner.Record(&recording)
+
+	var got []ServerSentEvent
+	for scanner.Next() {
+		got = append(got, scanner.Event())
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("recorded events = %v, want %v", got, want)
+	}
+
+	start := time.Now()
+	replayed := Replay(strings.NewReader(recording.String()), 1)
+	got = nil
+	for replayed.Next() {
+		got = append(got, replayed.Event())
+	}
+
+	if err := replayed.Err(); err != nil {
+		t.Errorf("replay error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed events = %v, want %v", got, want)
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("replay took %v, want the original timing of at least 40ms", elapsed)
+	}
+
+	// accelerated, from a file
+	path := filepath.Join(t.TempDir(), "stream.jsonl")
+	if err := os.WriteFile(path, []byte(recording.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, err := ReplayFile(path, 0)
+	if err != nil {
+		t.Fatalf("ReplayFile error: %v", err)
+	}
+	defer replayed.Close()
+
+	start = time.Now()
+	got = nil
+	for replayed.Next() {
+		got = append(got, replayed.Event())
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed events = %v, want %v", got, want)
+	}
+
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("replay without delays took %v", elapsed)
+	}
+}
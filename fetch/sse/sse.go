@@ -2,6 +2,8 @@ package sse
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
 	"io"
 	"strconv"
 	"strings"
@@ -90,6 +92,7 @@ type Scanner struct {
 	next        ServerSentEvent
 	err         error
 	readComment bool
+	maxLineSize int
 }
 
 func NewScanner(r io.Reader, readComment bool) *Scanner {
@@ -123,6 +126,24 @@ func (s *Scanner) setReader(r io.Reader) {
 	scanner := bufio.NewScanner(r)
 	scanner.Split(NewEOLSplitterFunc())
 	s.scanner = scanner
+	s.setBuffer()
+}
+
+// SetMaxLineSize sets the maximum size of a line in the stream, which is
+// bufio.MaxScanTokenSize (64KB) by default. Lines longer than that stop the
+// scanner with an error wrapping bufio.ErrTooLong. It must be called before
+// the first call to Next.
+func (s *Scanner) SetMaxLineSize(n int) {
+	s.maxLineSize = n
+	s.setBuffer()
+}
+
+func (s *Scanner) setBuffer() {
+	if s.maxLineSize <= 0 {
+		return
+	}
+
+	s.scanner.Buffer(make([]byte, 0, min(s.maxLineSize, bufio.MaxScanTokenSize)), s.maxLineSize)
 }
 
 // Tee
@@ -189,6 +210,13 @@ func (s *Scanner) Next() bool {
 	}
 
 	s.err = s.scanner.Err()
+	if errors.Is(s.err, bufio.ErrTooLong) {
+		maxLineSize := s.maxLineSize
+		if maxLineSize <= 0 {
+			maxLineSize = bufio.MaxScanTokenSize
+		}
+		s.err = fmt.Errorf("sse: line exceeds max line size of %d bytes: %w", maxLineSize, s.err)
+	}
 
 	if !seenNonEmptyLine {
 		return false
@@ -0,0 +1,133 @@
+package sse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Writer writes server-sent events to an http.ResponseWriter, flushing after
+// every event. With echo, pass c.Response():
+//
+//	w, err := sse.NewWriter(c.Response())
+//	stop := w.KeepAlive(15 * time.Second)
+//	defer stop()
+//	w.Send(sse.ServerSentEvent{Event: "update", Data: data})
+//
+// It is safe for concurrent use.
+type Writer struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+
+	mu sync.Mutex
+}
+
+// NewWriter sets the event stream headers, and writes the response header.
+// It fails if w doesn't support flushing.
+func NewWriter(w http.ResponseWriter) (*Writer, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("sse: response writer does not support flushing")
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &Writer{w: w, flusher: flusher}, nil
+}
+
+// Send writes the event. Multi-line data is sent as multiple data lines.
+func (w *Writer) Send(ev ServerSentEvent) error {
+	var buf bytes.Buffer
+	WriteEvent(&buf, ev)
+
+	return w.write(buf.Bytes())
+}
+
+// Comment writes a comment line, which clients ignore.
+func (w *Writer) Comment(text string) error {
+	var buf bytes.Buffer
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(&buf, ":%s\n", line)
+	}
+	buf.WriteString("\n")
+
+	return w.write(buf.Bytes())
+}
+
+// KeepAlive sends a comment every interval, so proxies don't close an idle
+// stream. Call stop when done writing.
+func (w *Writer) KeepAlive(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := w.Comment("keepalive"); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+func (w *Writer) write(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.w.Write(data)
+	if err != nil {
+		return err
+	}
+
+	w.flusher.Flush()
+	return nil
+}
+
+// WriteEvent writes the event in the text/event-stream format.
+func WriteEvent(w io.Writer, ev ServerSentEvent) {
+	if ev.ID != "" {
+		fmt.Fprintf(w, "id: %s\n", ev.ID)
+	}
+
+	if ev.Event != "" {
+		fmt.Fprintf(w, "event: %s\n", ev.Event)
+	}
+
+	if ev.Retry > 0 {
+		fmt.Fprintf(w, "retry: %d\n", ev.Retry)
+	}
+
+	if ev.Comment != "" {
+		for _, line := range strings.Split(ev.Comment, "\n") {
+			fmt.Fprintf(w, ":%s\n", line)
+		}
+	}
+
+	for _, line := range strings.Split(ev.Data, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+
+	fmt.Fprint(w, "\n")
+}
@@ -0,0 +1,231 @@
+package fetch
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const unixScheme = "unix://"
+
+// unixSocket returns the path of the unix socket to connect to, from
+// UnixSocket or a unix:// BaseURL.
+func (o *Options) unixSocket() string {
+	if o.UnixSocket != "" {
+		return o.UnixSocket
+	}
+
+	if strings.HasPrefix(o.BaseURL, unixScheme) {
+		return strings.TrimPrefix(o.BaseURL, unixScheme)
+	}
+
+	return ""
+}
+
+// transport is an http.Transport built from the transport options. Clients
+// built by NewClient use it, so the options are not applied twice. Requests
+// with other transport options derive a new transport from base.
+type transport struct {
+	*http.Transport
+
+	base     *http.Transport
+	settings transportSettings
+}
+
+// transportSettings are the transport options a transport was built with.
+type transportSettings struct {
+	tlsConfig           *tls.Config
+	rootCAs             *x509.CertPool
+	certificates        []tls.Certificate
+	insecureSkipVerify  bool
+	minTLSVersion       uint16
+	unixSocket          string
+	proxy               string
+	proxyFunc           func(req *http.Request) (*url.URL, error)
+	proxyHeader         http.Header
+	resolver            Resolver
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	idleConnTimeout     time.Duration
+	forceHTTP2          bool
+}
+
+func (o *Options) transportSettings() transportSettings {
+	return transportSettings{
+		tlsConfig:           o.TLSConfig,
+		rootCAs:             o.RootCAs,
+		certificates:        o.Certificates,
+		insecureSkipVerify:  o.InsecureSkipVerify,
+		minTLSVersion:       o.MinTLSVersion,
+		unixSocket:          o.unixSocket(),
+		proxy:               o.Proxy,
+		proxyFunc:           o.ProxyFunc,
+		proxyHeader:         o.ProxyHeader,
+		resolver:            o.Resolver,
+		maxIdleConnsPerHost: o.MaxIdleConnsPerHost,
+		maxConnsPerHost:     o.MaxConnsPerHost,
+		idleConnTimeout:     o.IdleConnTimeout,
+		forceHTTP2:          o.ForceHTTP2,
+	}
+}
+
+// equal reports whether both settings build the same transport. Slices and
+// maps are compared by identity. Funcs can't be compared, so settings with a
+// ProxyFunc are never equal.
+func (s transportSettings) equal(other transportSettings) bool {
+	return s.tlsConfig == other.tlsConfig &&
+		s.rootCAs == other.rootCAs &&
+		sameSlice(s.certificates, other.certificates) &&
+		s.insecureSkipVerify == other.insecureSkipVerify &&
+		s.minTLSVersion == other.minTLSVersion &&
+		s.unixSocket == other.unixSocket &&
+		s.proxy == other.proxy &&
+		s.proxyFunc == nil && other.proxyFunc == nil &&
+		reflect.ValueOf(s.proxyHeader).UnsafePointer() == reflect.ValueOf(other.proxyHeader).UnsafePointer() &&
+		sameResolver(s.resolver, other.resolver) &&
+		s.maxIdleConnsPerHost == other.maxIdleConnsPerHost &&
+		s.maxConnsPerHost == other.maxConnsPerHost &&
+		s.idleConnTimeout == other.idleConnTimeout &&
+		s.forceHTTP2 == other.forceHTTP2
+}
+
+func sameSlice[T any](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	return len(a) == 0 || &a[0] == &b[0]
+}
+
+func sameResolver(a, b Resolver) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+
+	return a == b
+}
+
+// hasTransportOptions reports whether any option requires building a
+// transport.
+func (o *Options) hasTransportOptions() bool {
+	return o.TLSConfig != nil ||
+		o.RootCAs != nil ||
+		len(o.Certificates) > 0 ||
+		o.InsecureSkipVerify ||
+		o.MinTLSVersion != 0 ||
+		o.unixSocket() != "" ||
+		o.Proxy != "" ||
+		o.ProxyFunc != nil ||
+		o.ProxyHeader != nil ||
+		o.Resolver != nil ||
+		o.MaxIdleConnsPerHost > 0 ||
+		o.MaxConnsPerHost > 0 ||
+		o.IdleConnTimeout > 0 ||
+		o.ForceHTTP2
+}
+
+// newTransport builds a transport with the transport options, starting from
+// a clone of the client's transport (or http.DefaultTransport). For a
+// transport built by NewClient, it starts from the transport that one was
+// built from, so that the options replace the ones it was built with.
+func (o *Options) newTransport() (*transport, error) {
+	base := http.DefaultTransport
+	if o.Client != nil && o.Client.Transport != nil {
+		base = o.Client.Transport
+	}
+
+	var t, orig *http.Transport
+	switch base := base.(type) {
+	case *http.Transport:
+		orig = base
+		t = base.Clone()
+	case *transport:
+		orig = base.base
+		t = base.base.Clone()
+	default:
+		return nil, errors.New("fetch: transport options require Client.Transport to be an *http.Transport")
+	}
+
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+
+	if o.TLSConfig != nil {
+		t.TLSClientConfig = o.TLSConfig.Clone()
+	}
+
+	tlsConfig := t.TLSClientConfig
+
+	if o.RootCAs != nil {
+		tlsConfig.RootCAs = o.RootCAs
+	}
+
+	if len(o.Certificates) > 0 {
+		tlsConfig.Certificates = append(tlsConfig.Certificates, o.Certificates...)
+	}
+
+	if o.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	if o.MinTLSVersion != 0 {
+		tlsConfig.MinVersion = o.MinTLSVersion
+	}
+
+	if o.Proxy != "" {
+		proxyURL, err := url.Parse(o.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("fetch: invalid proxy: %w", err)
+		}
+		t.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if o.ProxyFunc != nil {
+		t.Proxy = o.ProxyFunc
+	}
+
+	if o.ProxyHeader != nil {
+		t.ProxyConnectHeader = o.ProxyHeader.Clone()
+	}
+
+	if o.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+
+	if o.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = o.MaxConnsPerHost
+	}
+
+	if o.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = o.IdleConnTimeout
+	}
+
+	if o.ForceHTTP2 {
+		t.ForceAttemptHTTP2 = true
+	}
+
+	if o.Resolver != nil {
+		t.DialContext = resolvingDialer(o.Resolver)
+	}
+
+	if socket := o.unixSocket(); socket != "" {
+		var dialer net.Dialer
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+	}
+
+	return &transport{Transport: t, base: orig, settings: o.transportSettings()}, nil
+}
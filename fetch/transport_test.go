@@ -0,0 +1,54 @@
+package fetch
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionPoolOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	base := &Options{MaxIdleConnsPerHost: 32, IdleConnTimeout: time.Minute}
+	opts := base.Merge(&Options{MaxConnsPerHost: 64, ForceHTTP2: true})
+
+	client, err := NewClient(opts)
+	assert.NoError(err)
+
+	tr, ok := client.Transport.(*transport)
+	assert.True(ok)
+	assert.Equal(32, tr.MaxIdleConnsPerHost)
+	assert.Equal(64, tr.MaxConnsPerHost)
+	assert.Equal(time.Minute, tr.IdleConnTimeout)
+	assert.True(tr.ForceAttemptHTTP2)
+}
+
+func TestPrebuiltClientTransportOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	base := &Options{MaxIdleConnsPerHost: 32, InsecureSkipVerify: true}
+	client, err := NewClient(base)
+	assert.NoError(err)
+	base.Client = client
+
+	// the base options are already applied
+	opts := base.Merge(nil)
+	assert.False(opts.needsTransport())
+
+	// per-call transport options derive a new transport
+	opts = base.Merge(&Options{MinTLSVersion: tls.VersionTLS13})
+	assert.True(opts.needsTransport())
+
+	derived, err := NewClient(opts)
+	assert.NoError(err)
+
+	tr := derived.Transport.(*transport)
+	assert.Equal(32, tr.MaxIdleConnsPerHost)
+	assert.True(tr.TLSClientConfig.InsecureSkipVerify)
+	assert.Equal(uint16(tls.VersionTLS13), tr.TLSClientConfig.MinVersion)
+
+	// the prebuilt transport is unchanged
+	assert.Zero(client.Transport.(*transport).TLSClientConfig.MinVersion)
+}
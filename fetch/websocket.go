@@ -0,0 +1,92 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// WebSocketConn is a websocket connection with JSON helpers.
+type WebSocketConn struct {
+	*websocket.Conn
+
+	ctx     context.Context
+	stop    func() bool
+	release func()
+}
+
+// WebSocket opens a websocket connection to the resource. The URL, headers and
+// auth are built like the other requests, and http(s) URLs are upgraded to
+// ws(s).
+//
+// The connection closes with StatusGoingAway when Options.Context is done,
+// e.g. when the goo.ShutdownContext is cancelled.
+func WebSocket(resource string, opts *Options) (*WebSocketConn, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	req, err := NewRequest(http.MethodGet, resource, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	client, release, err := opts.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := req.Context()
+
+	opts.logger().Debug("fetch.WebSocket", "url", req.URL.String())
+
+	conn, res, err := websocket.Dial(ctx, req.URL.String(), &websocket.DialOptions{
+		HTTPClient: client,
+		HTTPHeader: req.Header,
+	})
+	if err != nil {
+		release()
+
+		if res != nil && res.StatusCode >= 400 {
+			return nil, &JSONError{JSONResponse: &JSONResponse{response: res}}
+		}
+
+		return nil, err
+	}
+
+	c := &WebSocketConn{Conn: conn, ctx: ctx, release: release}
+	c.stop = context.AfterFunc(ctx, func() {
+		c.Conn.Close(websocket.StatusGoingAway, "shutting down")
+		release()
+	})
+
+	return c, nil
+}
+
+// WebSocket opens a websocket connection with the merged options.
+func (o *Options) WebSocket(resource string, opts *Options) (*WebSocketConn, error) {
+	return WebSocket(resource, o.Merge(opts))
+}
+
+// WriteJSON sends v as a JSON text message.
+func (c *WebSocketConn) WriteJSON(v any) error {
+	return wsjson.Write(c.ctx, c.Conn, v)
+}
+
+// ReadJSON receives a JSON message into v.
+func (c *WebSocketConn) ReadJSON(v any) error {
+	return wsjson.Read(c.ctx, c.Conn, v)
+}
+
+// Close closes the connection with StatusNormalClosure.
+func (c *WebSocketConn) Close() error {
+	if !c.stop() {
+		// closed by the context
+		return nil
+	}
+
+	defer c.release()
+	return c.Conn.Close(websocket.StatusNormalClosure, "")
+}
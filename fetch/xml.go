@@ -0,0 +1,91 @@
+package fetch
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type XMLResponse struct {
+	response *http.Response
+
+	body []byte
+}
+
+// Response returns the original http.Response.
+func (r *XMLResponse) Response() *http.Response {
+	return r.response
+}
+
+// Unmarshal decodes the XML response from the server.
+func (r *XMLResponse) Unmarshal(v interface{}) error {
+	return xml.Unmarshal(r.body, v)
+}
+
+// Body returns the body of the response.
+func (r *XMLResponse) Body() []byte {
+	return r.body
+}
+
+// String returns the body of the response as a string.
+func (r *XMLResponse) String() string {
+	return string(r.body)
+}
+
+type XMLError struct {
+	*XMLResponse
+}
+
+func (e *XMLError) Error() string {
+	return fmt.Sprintf("fetch XML error: %d %s", e.response.StatusCode, e.response.Status)
+}
+
+// XML creates a new request and executes it as an XML request. Struct bodies
+// are marshalled as XML when the Content-Type header is XML, and Unmarshal is
+// decoded with encoding/xml. Accept defaults to application/xml.
+func XML(method, resource string, opts *Options) (*XMLResponse, error) {
+	opts.logger().Debug("fetch.XML", "method", method, "url", resource)
+
+	reqOpts := *opts
+	if opts.Header.Get("Accept") == "" {
+		reqOpts.Header = opts.Header.Clone()
+		reqOpts.SetHeader("Accept", "application/xml")
+	}
+
+	res, err := reqOpts.Do(method, resource)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	xres := &XMLResponse{
+		response: res,
+		body:     body,
+	}
+
+	if res.StatusCode >= 400 {
+		opts.logger().Debug("fetch.XML error", "body", string(opts.Redact.Body(body)))
+		return xres, &XMLError{xres}
+	}
+
+	if opts.Unmarshal != nil {
+		err = xml.Unmarshal(body, opts.Unmarshal)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return xres, nil
+}
+
+// XML creates a new request and executes it as an XML request.
+func (o *Options) XML(method, resource string, opts *Options) (*XMLResponse, error) {
+	opts2 := o.Merge(opts)
+	return XML(method, resource, opts2)
+}
@@ -1,6 +1,7 @@
 package goo
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/pelletier/go-toml/v2"
@@ -114,57 +116,83 @@ func Decode(r io.Reader, format string, o interface{}) error {
 	return nil
 }
 
+// DecodeFile decodes the file into the object, using the file extension to
+// determine the format. The files listed by a top-level "include" key, relative
+// to the file, are merged in first, and the file itself over them.
 func DecodeFile(file string, o interface{}) error {
-	ext := strings.ToLower(filepath.Ext(file))
-
-	r, err := os.Open(file)
+	data, format, err := readConfigFile(file)
 	if err != nil {
-		return fmt.Errorf("decode: %w", err)
+		return err
 	}
-	defer r.Close()
 
-	// .toml -> "toml"
-	format := strings.TrimPrefix(ext, ".")
-
-	return Decode(r, format, o)
+	return Decode(bytes.NewReader(data), format, o)
 }
 
 // DecodeURL parses the data URL and decodes the content into the provided object.
+// The URL may be a file path, an http(s) URL, or an s3://bucket/key URI. The
+// format is determined by the extension of the path.
 func DecodeURL(dataurl string, o interface{}) error {
+	data, err := readURL(dataurl, nil)
+	if err != nil {
+		return fmt.Errorf("decodeURL: %w", err)
+	}
+
+	return Decode(bytes.NewReader(data), urlFormat(dataurl), o)
+}
+
+// urlFormat returns the format of the extension of the URL path, e.g. "toml".
+func urlFormat(dataurl string) string {
+	path := dataurl
+	if u, err := url.Parse(dataurl); err == nil {
+		path = u.Path
+	}
+
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+}
+
+// remoteClient fetches remote configs. The timeout bounds each attempt, so
+// that a hanging endpoint is retried, and falls back to the cached config.
+var remoteClient = &http.Client{Timeout: 30 * time.Second}
+
+// readURL reads a file path, an http(s) URL with the header, or an s3 URI.
+func readURL(dataurl string, header http.Header) ([]byte, error) {
 	var scheme string
 	parsedURL, err := url.Parse(dataurl)
-	if err != nil {
-		// if invalid url, just treat it as file path
-		err = nil
-	} else {
+	if err == nil {
 		scheme = parsedURL.Scheme
 	}
-
-	var r io.ReadCloser
+	// if invalid url, just treat it as file path
 
 	switch scheme {
 	case "http", "https":
-		resp, err := http.Get(dataurl)
+		req, err := http.NewRequest("GET", dataurl, nil)
 		if err != nil {
-			return fmt.Errorf("decodeURL: http get: %w", err)
+			return nil, fmt.Errorf("http get: %w", err)
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		resp, err := remoteClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("http get: %w", err)
 		}
 		defer resp.Body.Close()
 		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("decodeURL: http get: non-200 status code: %d", resp.StatusCode)
+			return nil, fmt.Errorf("http get: non-200 status code: %d", resp.StatusCode)
 		}
-		r = resp.Body
+
+		return io.ReadAll(resp.Body)
+	case "s3":
+		return s3Get(parsedURL)
 	case "":
-		r, err = os.Open(dataurl)
+		data, err := os.ReadFile(dataurl)
 		if err != nil {
-			return fmt.Errorf("decodeURL: open file: %w", err)
+			return nil, fmt.Errorf("open file: %w", err)
 		}
-		defer r.Close()
+
+		return data, nil
 	default:
-		return fmt.Errorf("decodeURL: unknown protocol: %s", parsedURL.Scheme)
+		return nil, fmt.Errorf("unknown protocol: %s", parsedURL.Scheme)
 	}
-
-	ext := strings.ToLower(filepath.Ext(parsedURL.Path))
-	format := strings.TrimPrefix(ext, ".")
-
-	return Decode(r, format, o)
 }
@@ -0,0 +1,68 @@
+package goo
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// configExts are the extensions of config files, in order of preference.
+var configExts = []string{TOMLFormat, YAMLFormat, JSONFormat, JSONCFormat}
+
+// FindConfigFile returns the first config file of the app found in:
+//
+//	./{appName}.{toml,yaml,json,jsonc}
+//	$XDG_CONFIG_HOME/{appName}/config.*    (~/.config by default)
+//	{user config dir}/{appName}/config.*   (e.g. ~/Library/Application Support on macOS, %AppData% on Windows)
+//	/etc/{appName}/config.*                (%ProgramData% on Windows)
+//
+// ParseConfig falls back to it when no config environment variable is set.
+func FindConfigFile(appName string) (string, bool) {
+	for _, path := range configFileCandidates(appName) {
+		info, err := os.Stat(path)
+		if err == nil && info.Mode().IsRegular() {
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+func configFileCandidates(appName string) []string {
+	var dirs []string
+
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			xdg = filepath.Join(home, ".config")
+		}
+	}
+	if xdg != "" {
+		dirs = append(dirs, filepath.Join(xdg, appName))
+	}
+
+	if dir, err := os.UserConfigDir(); err == nil && dir != xdg {
+		dirs = append(dirs, filepath.Join(dir, appName))
+	}
+
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("ProgramData"); dir != "" {
+			dirs = append(dirs, filepath.Join(dir, appName))
+		}
+	} else {
+		dirs = append(dirs, filepath.Join("/etc", appName))
+	}
+
+	var paths []string
+	for _, ext := range configExts {
+		paths = append(paths, appName+"."+ext)
+	}
+
+	for _, dir := range dirs {
+		for _, ext := range configExts {
+			paths = append(paths, filepath.Join(dir, "config."+ext))
+		}
+	}
+
+	return paths
+}
@@ -0,0 +1,51 @@
+package goo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindConfigFile(t *testing.T) {
+	assert := assert.New(t)
+
+	wd, err := os.Getwd()
+	assert.NoError(err)
+	assert.NoError(os.Chdir(t.TempDir()))
+	defer os.Chdir(wd)
+
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	_, ok := FindConfigFile("gootestapp")
+	assert.False(ok)
+
+	_, err = ParseConfig[Config]("gootestapp")
+	assert.True(errors.Is(err, ErrNoConfig))
+
+	userConfig := filepath.Join(xdg, "gootestapp", "config.yaml")
+	assert.NoError(os.MkdirAll(filepath.Dir(userConfig), 0o755))
+	assert.NoError(os.WriteFile(userConfig, []byte("logging:\n  loglevel: debug\n"), 0o644))
+
+	path, ok := FindConfigFile("gootestapp")
+	assert.True(ok)
+	assert.Equal(userConfig, path)
+
+	cfg, err := ParseConfig[Config]("gootestapp")
+	assert.NoError(err)
+	assert.Equal("debug", cfg.Logging.LogLevel)
+
+	// the working directory comes first
+	assert.NoError(os.WriteFile("gootestapp.toml", []byte("[Logging]\nLogLevel = \"warn\"\n"), 0o644))
+
+	path, ok = FindConfigFile("gootestapp")
+	assert.True(ok)
+	assert.Equal("gootestapp.toml", path)
+
+	cfg, err = ParseConfig[Config]("gootestapp")
+	assert.NoError(err)
+	assert.Equal("warn", cfg.Logging.LogLevel)
+}
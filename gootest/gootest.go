@@ -0,0 +1,167 @@
+// Package gootest helps test the lifecycle of goo apps: shutdown on signals,
+// exit functions and exit codes, and time-driven code like the Scheduler,
+// without touching the process-wide lifecycle.
+//
+//	l := gootest.NewLifecycle(t)
+//	app := NewApp(l.ShutdownContext)
+//
+//	l.SendSignal(syscall.SIGTERM)
+//	assert.Equal(t, 0, l.WaitExit(t))
+package gootest
+
+import (
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hayeah/goo"
+)
+
+// exitTimeout bounds how long WaitExit waits for an exit.
+const exitTimeout = 5 * time.Second
+
+// Lifecycle is a goo.Lifecycle that ignores OS signals, and records exits
+// instead of exiting the process.
+type Lifecycle struct {
+	*goo.Lifecycle
+
+	Exits *ExitRecorder
+}
+
+// NewLifecycle starts a Lifecycle, stopped when the test ends.
+func NewLifecycle(t testing.TB) *Lifecycle {
+	exits := NewExitRecorder()
+
+	l := goo.NewLifecycle(slog.Default())
+	l.Signals = []os.Signal{}
+	l.Exit = exits.Exit
+	l.Start()
+	t.Cleanup(l.Stop)
+
+	return &Lifecycle{Lifecycle: l, Exits: exits}
+}
+
+// SendSignal delivers the signal as if the process received it. SIGHUP runs
+// the reload functions, and other signals shut down.
+func (l *Lifecycle) SendSignal(sig os.Signal) {
+	l.Deliver(sig)
+}
+
+// WaitExit waits for the exit, and returns its code.
+func (l *Lifecycle) WaitExit(t testing.TB) int {
+	t.Helper()
+	return l.Exits.Wait(t)
+}
+
+// ExitRecorder records exit codes, in place of os.Exit.
+type ExitRecorder struct {
+	codes chan int
+}
+
+// NewExitRecorder creates an ExitRecorder.
+func NewExitRecorder() *ExitRecorder {
+	return &ExitRecorder{codes: make(chan int, 16)}
+}
+
+// Exit records the exit code.
+func (r *ExitRecorder) Exit(code int) {
+	r.codes <- code
+}
+
+// Wait waits for an exit, and returns its code. The test fails if there's no
+// exit within 5 seconds.
+func (r *ExitRecorder) Wait(t testing.TB) int {
+	t.Helper()
+
+	select {
+	case code := <-r.codes:
+		return code
+	case <-time.After(exitTimeout):
+		t.Fatalf("gootest: no exit after %v", exitTimeout)
+		return -1
+	}
+}
+
+// FakeClock is a goo.Clock that only moves forward with Advance.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []waiter
+}
+
+type waiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+var _ goo.Clock = (*FakeClock)(nil)
+
+// NewFakeClock creates a FakeClock at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After sends the time once the clock advanced by d.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, waiter{at: c.now.Add(d), ch: ch})
+	c.cond.Broadcast()
+
+	return ch
+}
+
+// Advance moves the clock forward by d, and fires the timers due by then.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	sort.Slice(c.waiters, func(i, j int) bool {
+		return c.waiters[i].at.Before(c.waiters[j].at)
+	})
+
+	var pending []waiter
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+// BlockUntil waits until n timers are pending, so that Advance doesn't race
+// with the code under test setting its timers.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
@@ -0,0 +1,87 @@
+package gootest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hayeah/goo"
+)
+
+func TestLifecycle(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewLifecycle(t)
+
+	reloaded := make(chan struct{})
+	l.OnReload(func(ctx context.Context) error {
+		close(reloaded)
+		return nil
+	})
+
+	var exited bool
+	l.OnExit(func() error {
+		exited = true
+		return nil
+	})
+
+	l.SendSignal(syscall.SIGHUP)
+	<-reloaded
+
+	l.SendSignal(syscall.SIGTERM)
+
+	assert.Equal(0, l.WaitExit(t))
+	assert.True(exited)
+	assert.Equal(syscall.SIGTERM, l.Signal())
+}
+
+func TestLifecycleExitCode(t *testing.T) {
+	l := NewLifecycle(t)
+
+	l.ShutdownWithError(&goo.ExitError{Code: 3, Err: errors.New("failed")})
+	assert.Equal(t, 3, l.WaitExit(t))
+}
+
+func TestFakeClock(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	var runs int
+	scheduler, err := goo.ProvideScheduler(slog.Default(), []goo.Job{{
+		Name:     "report",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			runs++
+			return nil
+		},
+	}})
+	assert.NoError(err)
+	scheduler.Clock = clock
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	for i := 0; i < 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+	}
+
+	// the third run is done once the next timer is set
+	clock.BlockUntil(1)
+	cancel()
+	<-done
+
+	assert.Equal(3, runs)
+	assert.Equal(start.Add(3*time.Hour), clock.Now())
+}
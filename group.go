@@ -0,0 +1,66 @@
+package goo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// RunnerGroup runs long-lived services (HTTP servers, queue consumers, cron)
+// concurrently with the ShutdownContext. When a service fails, the group shuts
+// down the process with its error, so the other services stop too.
+//
+//	group.Go("http", func(ctx context.Context) error { ... })
+//	group.Go("consumer", consumer.Run)
+//	err := group.Wait()
+type RunnerGroup struct {
+	ctx *ShutdownContext
+	log *slog.Logger
+
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+}
+
+// ProvideRunnerGroup creates a RunnerGroup bound to the ShutdownContext.
+func ProvideRunnerGroup(ctx *ShutdownContext, log *slog.Logger) *RunnerGroup {
+	return &RunnerGroup{ctx: ctx, log: log.With("_type", "RunnerGroup")}
+}
+
+// Go starts the service. ctx is done when the process shuts down, and exit
+// waits for the service to return before running the exit functions.
+//
+// The service runs in a new goroutine. If the shutdown begins before the
+// goroutine starts, e.g. because another service failed right away, the
+// service is silently skipped and never runs.
+func (g *RunnerGroup) Go(name string, run func(ctx context.Context) error) {
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		err := g.ctx.BlockExit(func() error {
+			g.log.Debug("runner started", "name", name)
+			return run(g.ctx)
+		})
+
+		if err == nil || err == ErrShutdown {
+			g.log.Debug("runner stopped", "name", name)
+			return
+		}
+
+		g.log.Error("runner failed", "name", name, "error", err.Error())
+
+		g.errOnce.Do(func() {
+			g.err = fmt.Errorf("%s: %w", name, err)
+			g.ctx.ShutdownWithError(g.err)
+		})
+	}()
+}
+
+// Wait waits for all the services to return, and returns the first error.
+func (g *RunnerGroup) Wait() error {
+	g.wg.Wait()
+	return g.err
+}
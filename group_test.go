@@ -0,0 +1,48 @@
+package goo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunnerGroup(t *testing.T) {
+	assert := assert.New(t)
+
+	exited := make(chan int, 1)
+	l := NewLifecycle(nil)
+	l.Signals = []os.Signal{}
+	l.Exit = func(code int) { exited <- code }
+	l.Start()
+	defer l.Stop()
+
+	group := ProvideRunnerGroup(l.ShutdownContext, slog.Default())
+
+	started := make(chan struct{})
+	var stopped bool
+	group.Go("server", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		stopped = true
+		return nil
+	})
+
+	failure := errors.New("connection lost")
+	group.Go("consumer", func(ctx context.Context) error {
+		<-started
+		return failure
+	})
+
+	err := group.Wait()
+	assert.ErrorIs(err, failure)
+	assert.EqualError(err, "consumer: connection lost")
+	assert.True(stopped)
+
+	// the failure shuts down the process with an error
+	assert.Equal(1, <-exited)
+	assert.ErrorIs(context.Cause(l), failure)
+}
@@ -0,0 +1,127 @@
+package goo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// healthCheckTimeout bounds the checkers of a readiness check.
+const healthCheckTimeout = 5 * time.Second
+
+// HealthStatus is the result of a health check.
+type HealthStatus struct {
+	// Status is "ok", "failing" or "shutting down".
+	Status string `json:"status"`
+	// Checks are the results of the checkers by name, "ok" or the error.
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// OK reports whether the check passed.
+func (s HealthStatus) OK() bool {
+	return s.Status == "ok"
+}
+
+// Health aggregates health checkers into liveness and readiness checks, served
+// at /healthz and /readyz. Readiness fails as soon as shutdown begins, so load
+// balancers stop sending traffic while the process drains.
+type Health struct {
+	ctx *ShutdownContext
+
+	mu       sync.Mutex
+	checkers map[string]func(ctx context.Context) error
+}
+
+// NewHealth creates a Health of the process.
+func NewHealth(ctx *ShutdownContext) *Health {
+	return &Health{ctx: ctx, checkers: map[string]func(ctx context.Context) error{}}
+}
+
+// ProvideHealth creates a Health, and mounts its endpoints on the echo server.
+func ProvideHealth(ctx *ShutdownContext, e *echo.Echo) *Health {
+	h := NewHealth(ctx)
+	h.Mount(e)
+	return h
+}
+
+// RegisterChecker adds a readiness checker, e.g. pinging the database.
+func (h *Health) RegisterChecker(name string, check func(ctx context.Context) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checkers[name] = check
+}
+
+// Live reports whether the process is alive. It doesn't run the checkers.
+func (h *Health) Live(ctx context.Context) HealthStatus {
+	return HealthStatus{Status: "ok"}
+}
+
+// Ready runs the checkers concurrently, and reports whether the process can
+// serve traffic.
+func (h *Health) Ready(ctx context.Context) HealthStatus {
+	if h.ctx.Err() != nil {
+		return HealthStatus{Status: "shutting down"}
+	}
+
+	h.mu.Lock()
+	checkers := make(map[string]func(ctx context.Context) error, len(h.checkers))
+	for name, check := range h.checkers {
+		checkers[name] = check
+	}
+	h.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	status := HealthStatus{Status: "ok", Checks: map[string]string{}}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := "ok"
+			if err := check(ctx); err != nil {
+				result = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			status.Checks[name] = result
+			if result != "ok" {
+				status.Status = "failing"
+			}
+		}()
+	}
+	wg.Wait()
+
+	return status
+}
+
+// Mount serves the liveness check at /healthz and the readiness check at
+// /readyz. Failing checks respond with 503.
+func (h *Health) Mount(e *echo.Echo) {
+	e.GET("/healthz", func(c echo.Context) error {
+		return respondHealth(c, h.Live(c.Request().Context()))
+	})
+
+	e.GET("/readyz", func(c echo.Context) error {
+		return respondHealth(c, h.Ready(c.Request().Context()))
+	})
+}
+
+func respondHealth(c echo.Context, status HealthStatus) error {
+	code := http.StatusOK
+	if !status.OK() {
+		code = http.StatusServiceUnavailable
+	}
+
+	return c.JSON(code, status)
+}
@@ -0,0 +1,53 @@
+package goo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	assert := assert.New(t)
+
+	shutdown := NewLifecycle(slog.Default()).ShutdownContext
+
+	e := echo.New()
+	health := ProvideHealth(shutdown, e)
+
+	dbErr := errors.New("connection refused")
+	health.RegisterChecker("cache", func(ctx context.Context) error { return nil })
+	health.RegisterChecker("db", func(ctx context.Context) error { return dbErr })
+
+	code, body := serveGet(e, "/readyz")
+	assert.Equal(http.StatusServiceUnavailable, code)
+	assert.JSONEq(`{"status": "failing", "checks": {"cache": "ok", "db": "connection refused"}}`, body)
+
+	dbErr = nil
+	code, body = serveGet(e, "/readyz")
+	assert.Equal(http.StatusOK, code)
+	assert.JSONEq(`{"status": "ok", "checks": {"cache": "ok", "db": "ok"}}`, body)
+
+	// readiness fails once shutdown begins, while the process stays live
+	shutdown.Shutdown()
+	code, body = serveGet(e, "/readyz")
+	assert.Equal(http.StatusServiceUnavailable, code)
+	assert.JSONEq(`{"status": "shutting down"}`, body)
+
+	code, body = serveGet(e, "/healthz")
+	assert.Equal(http.StatusOK, code)
+	assert.JSONEq(`{"status": "ok"}`, body)
+}
+
+// serveGet serves a GET request for the path with the handler, and returns
+// the status code and body of the response.
+func serveGet(h http.Handler, path string) (int, string) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec.Code, rec.Body.String()
+}
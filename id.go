@@ -0,0 +1,237 @@
+package goo
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UUIDColumn stores a UUID as its string, e.g.
+// "f47ac10b-58cc-4372-a567-0e02b2c3d479". It scans strings, and the 16 bytes
+// of BYTEA or BLOB columns. To write to these, pass UUID[:] instead.
+type UUIDColumn struct {
+	uuid.UUID
+}
+
+// ParseUUID parses a UUID string.
+func ParseUUID(s string) (UUIDColumn, error) {
+	u, err := uuid.Parse(s)
+	if err != nil {
+		return UUIDColumn{}, err
+	}
+
+	return UUIDColumn{u}, nil
+}
+
+func (u *UUIDColumn) Scan(src any) error {
+	return u.UUID.Scan(src)
+}
+
+func (u UUIDColumn) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+// ULID is a Universally Unique Lexicographically Sortable Identifier: 48 bits
+// of unix milliseconds followed by 80 random bits, sorted by creation time.
+// It's encoded as 26 characters of Crockford's base32.
+//
+// https://github.com/ulid/spec
+type ULID [16]byte
+
+// crockford is Crockford's base32 alphabet.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID generates a ULID of the time, with random bits from entropy.
+func NewULID(t time.Time, entropy io.Reader) (ULID, error) {
+	var id ULID
+
+	ms := uint64(t.UnixMilli())
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+
+	_, err := io.ReadFull(entropy, id[6:])
+	if err != nil {
+		return ULID{}, fmt.Errorf("ulid: %w", err)
+	}
+
+	return id, nil
+}
+
+// ParseULID parses a ULID string, case-insensitively.
+func ParseULID(s string) (ULID, error) {
+	var id ULID
+
+	if len(s) != 26 {
+		return id, fmt.Errorf("invalid ULID length: %q", s)
+	}
+
+	// the first character only has 3 bits, for 128 bits in 130
+	if s[0] > '7' {
+		return id, fmt.Errorf("invalid ULID: %q overflows 128 bits", s)
+	}
+
+	var bits uint
+	var acc uint64
+	i := 0
+	for j := 0; j < len(s); j++ {
+		v := strings.IndexByte(crockford, upperASCII(s[j]))
+		if v < 0 {
+			return ULID{}, fmt.Errorf("invalid ULID character %q: %q", s[j], s)
+		}
+
+		acc = acc<<5 | uint64(v)
+		bits += 5
+		if j == 0 {
+			// drop the 2 padding bits
+			bits -= 2
+		}
+
+		for bits >= 8 {
+			bits -= 8
+			id[i] = byte(acc >> bits)
+			i++
+		}
+	}
+
+	return id, nil
+}
+
+func upperASCII(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+
+	return c
+}
+
+// String encodes the ULID as 26 characters of Crockford's base32.
+func (id ULID) String() string {
+	var b [26]byte
+
+	// 130 bits, read 5 at a time from the end
+	var acc uint64
+	var bits uint
+	j := len(b) - 1
+	for i := len(id) - 1; i >= 0; i-- {
+		acc |= uint64(id[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			b[j] = crockford[acc&31]
+			j--
+			acc >>= 5
+			bits -= 5
+		}
+	}
+	b[0] = crockford[acc&31]
+
+	return string(b[:])
+}
+
+// Time is the creation time of the ULID, to the millisecond.
+func (id ULID) Time() time.Time {
+	ms := uint64(binary.BigEndian.Uint16(id[0:2]))<<32 | uint64(binary.BigEndian.Uint32(id[2:6]))
+	return time.UnixMilli(int64(ms))
+}
+
+// MarshalText
+func (id ULID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText
+func (id *ULID) UnmarshalText(data []byte) error {
+	parsed, err := ParseULID(string(data))
+	if err != nil {
+		return err
+	}
+
+	*id = parsed
+	return nil
+}
+
+// ULIDColumn stores a ULID as its string, which sorts as the ULIDs do. Like
+// UUIDColumn, it also scans the 16 bytes of BYTEA or BLOB columns.
+type ULIDColumn struct {
+	ULID
+}
+
+func (u *ULIDColumn) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return u.UnmarshalText([]byte(src))
+	case []byte:
+		if len(src) == len(u.ULID) {
+			copy(u.ULID[:], src)
+			return nil
+		}
+		return u.UnmarshalText(src)
+	default:
+		return fmt.Errorf("unsupported type: %T", src)
+	}
+}
+
+func (u ULIDColumn) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+// IDGenerator generates the IDs of the app. Inject it instead of calling
+// uuid.New, so that tests can generate predictable IDs with NewIDGenerator.
+type IDGenerator interface {
+	// UUID generates a random (version 4) UUID.
+	UUID() UUIDColumn
+	// ULID generates a ULID of the current time.
+	ULID() ULIDColumn
+}
+
+// ProvideIDGenerator provides an IDGenerator of the system clock and
+// crypto/rand.
+func ProvideIDGenerator() IDGenerator {
+	return NewIDGenerator(SystemClock, rand.Reader)
+}
+
+// NewIDGenerator creates an IDGenerator of the clock and random source, e.g. a
+// gootest.FakeClock and a seeded math/rand.Rand in tests. It panics if the
+// random source fails, as crypto/rand doesn't.
+func NewIDGenerator(clock Clock, random io.Reader) IDGenerator {
+	return &idGenerator{clock: clock, random: random}
+}
+
+type idGenerator struct {
+	clock Clock
+
+	mu     sync.Mutex
+	random io.Reader
+}
+
+func (g *idGenerator) UUID() UUIDColumn {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	u, err := uuid.NewRandomFromReader(g.random)
+	if err != nil {
+		panic(err)
+	}
+
+	return UUIDColumn{u}
+}
+
+func (g *idGenerator) ULID() ULIDColumn {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id, err := NewULID(g.clock.Now(), g.random)
+	if err != nil {
+		panic(err)
+	}
+
+	return ULIDColumn{id}
+}
@@ -0,0 +1,94 @@
+package goo
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedClock struct {
+	Clock
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestULID(t *testing.T) {
+	assert := assert.New(t)
+
+	// the example of the spec: 1469918176385 ms, and the max random bits
+	ts := time.UnixMilli(1469918176385)
+	id, err := NewULID(ts, bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)))
+	assert.NoError(err)
+	assert.Equal("01ARYZ6S41ZZZZZZZZZZZZZZZZ", id.String())
+	assert.Equal(ts, id.Time())
+
+	parsed, err := ParseULID("01aryz6s41zzzzzzzzzzzzzzzz")
+	assert.NoError(err)
+	assert.Equal(id, parsed)
+
+	_, err = ParseULID("81ARYZ6S41ZZZZZZZZZZZZZZZZ")
+	assert.ErrorContains(err, "overflows 128 bits")
+	_, err = ParseULID("01ARYZ6S41ZZZZZZZZZZZZZZZU")
+	assert.ErrorContains(err, "invalid ULID character")
+
+	// ULIDs sort by time
+	later, err := NewULID(ts.Add(time.Millisecond), bytes.NewReader(make([]byte, 10)))
+	assert.NoError(err)
+	assert.Less(id.String(), later.String())
+
+	data, err := json.Marshal(id)
+	assert.NoError(err)
+	assert.Equal(`"01ARYZ6S41ZZZZZZZZZZZZZZZZ"`, string(data))
+}
+
+func TestIDColumns(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	gen := NewIDGenerator(fixedClock{now: ts}, rand.New(rand.NewSource(1)))
+
+	u := gen.UUID()
+	ulid := gen.ULID()
+	assert.Equal(ts, ulid.Time().UTC())
+
+	// the same seed generates the same IDs
+	gen2 := NewIDGenerator(fixedClock{now: ts}, rand.New(rand.NewSource(1)))
+	assert.Equal(u, gen2.UUID())
+	assert.Equal(ulid, gen2.ULID())
+
+	db, err := sqlx.Open("sqlite3", filepath.Join(t.TempDir(), "app.db"))
+	assert.NoError(err)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE TABLE ids (uuid TEXT, ulid TEXT, uuid_bytes BLOB, ulid_bytes BLOB)")
+	assert.NoError(err)
+	_, err = db.Exec("INSERT INTO ids VALUES (?, ?, ?, ?)", u, ulid, u.UUID[:], ulid.ULID[:])
+	assert.NoError(err)
+
+	var row struct {
+		UUID      UUIDColumn
+		ULID      ULIDColumn
+		UUIDBytes UUIDColumn `db:"uuid_bytes"`
+		ULIDBytes ULIDColumn `db:"ulid_bytes"`
+	}
+	assert.NoError(db.Get(&row, "SELECT * FROM ids"))
+	assert.Equal(u, row.UUID)
+	assert.Equal(ulid, row.ULID)
+	assert.Equal(u, row.UUIDBytes)
+	assert.Equal(ulid, row.ULIDBytes)
+
+	var text string
+	assert.NoError(db.Get(&text, "SELECT ulid FROM ids"))
+	assert.Equal(ulid.String(), text)
+	assert.NoError(db.Get(&text, "SELECT uuid FROM ids"))
+	assert.Equal(u.String(), text)
+}
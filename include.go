@@ -0,0 +1,136 @@
+package goo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// includeKey is the top-level config key listing the files to include.
+const includeKey = "include"
+
+// readConfigFile reads a config file, and returns its data and format. If the
+// file has an include key, e.g.
+//
+//	include: [database.yaml, creds.toml]
+//
+// the included files are merged in order, and the file itself over them.
+// Paths are relative to the including file, and includes may be nested. The
+// merged config is returned as JSON.
+func readConfigFile(file string) ([]byte, string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, "", fmt.Errorf("decode: %w", err)
+	}
+
+	format := fileFormat(file)
+
+	var m map[string]any
+	err = Decode(bytes.NewReader(data), format, &m)
+	if err != nil {
+		// not a map, e.g. a list. Decoding into the object reports any error.
+		return data, format, nil
+	}
+
+	m, ok, err := includeConfig(file, m, nil)
+	if err != nil || !ok {
+		return data, format, err
+	}
+
+	merged, err := json.Marshal(m)
+	if err != nil {
+		return nil, "", fmt.Errorf("decode: %w", err)
+	}
+
+	return merged, JSONFormat, nil
+}
+
+// includeConfig merges the config m of the file over its includes. It returns
+// false if the file has no includes. stack holds the including files, to
+// detect cycles.
+func includeConfig(file string, m map[string]any, stack []string) (map[string]any, bool, error) {
+	key, ok := lookupKey(m, includeKey)
+	if !ok {
+		return m, false, nil
+	}
+
+	includes, err := includePaths(m[key])
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", file, err)
+	}
+	delete(m, key)
+
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, false, err
+	}
+	stack = append(stack, abs)
+
+	merged := map[string]any{}
+	for _, include := range includes {
+		path := include
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(file), path)
+		}
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if slices.Contains(stack, absPath) {
+			return nil, false, fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), absPath)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, false, fmt.Errorf("include: %w", err)
+		}
+
+		var included map[string]any
+		err = Decode(bytes.NewReader(data), fileFormat(path), &included)
+		if err != nil {
+			return nil, false, fmt.Errorf("include %s: %w", include, err)
+		}
+
+		included, _, err = includeConfig(path, included, stack)
+		if err != nil {
+			return nil, false, fmt.Errorf("include %s: %w", include, err)
+		}
+
+		mergeConfig(merged, included)
+	}
+
+	mergeConfig(merged, m)
+
+	return merged, true, nil
+}
+
+// includePaths returns the paths of an include value, a list or a string.
+func includePaths(v any) ([]string, error) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}, nil
+	case []any:
+		paths := make([]string, len(v))
+		for i, path := range v {
+			s, ok := path.(string)
+			if !ok {
+				return nil, fmt.Errorf("include: expected a path, got %v", path)
+			}
+			paths[i] = s
+		}
+		return paths, nil
+	default:
+		return nil, fmt.Errorf("include: expected a list of paths, got %v", v)
+	}
+}
+
+// fileFormat returns the format of the file extension, e.g. "toml".
+func fileFormat(file string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+}
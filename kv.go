@@ -0,0 +1,155 @@
+package goo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// KVSchema creates the goo_kv table of the KV. Add it to a migration of the
+// app, e.g. migrations/002_kv.up.sql. expires_at is in unix milliseconds, or
+// NULL if the key doesn't expire.
+const KVSchema = `
+CREATE TABLE IF NOT EXISTS goo_kv (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL,
+	expires_at BIGINT
+)`
+
+// KV is a key-value store in the goo_kv table of the database, for feature
+// flags, cursors or caches, without running Redis. Values are stored as JSON:
+//
+//	err := kv.Set(ctx, "sync:cursor", cursor, 0)
+//
+//	var cursor Cursor
+//	found, err := kv.Get(ctx, "sync:cursor", &cursor)
+//
+// Run it as a service of the RunnerGroup to delete the expired keys:
+//
+//	group.Go("kv", kv.Run)
+type KV struct {
+	// SweepInterval is how often Run deletes the expired keys. Defaults to a
+	// minute.
+	SweepInterval time.Duration
+	// Clock defaults to SystemClock.
+	Clock Clock
+
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+// ProvideKV creates the KV of the database, which must be sqlite or postgres,
+// and have the table of KVSchema.
+func ProvideKV(db *sqlx.DB, log *slog.Logger) (*KV, error) {
+	switch db.DriverName() {
+	case "sqlite3", "postgres", "pgx":
+	default:
+		return nil, fmt.Errorf("kv: unsupported dialect %s, only sqlite3 and postgres are supported", db.DriverName())
+	}
+
+	return &KV{db: db, log: log.With("_type", "KV")}, nil
+}
+
+// Get decodes the value of the key into v. It returns false if the key
+// doesn't exist or has expired.
+func (kv *KV) Get(ctx context.Context, key string, v any) (bool, error) {
+	var value JSONColumn[json.RawMessage]
+	err := kv.db.GetContext(ctx, &value, kv.db.Rebind(
+		"SELECT value FROM goo_kv WHERE key = ? AND (expires_at IS NULL OR expires_at > ?)"),
+		key, kv.clock().Now().UnixMilli())
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("kv: get %s: %w", key, err)
+	}
+
+	err = json.Unmarshal(value.V, v)
+	if err != nil {
+		return false, fmt.Errorf("kv: decode %s: %w", key, err)
+	}
+
+	return true, nil
+}
+
+// Set sets the key to the value encoded as JSON. The key expires after ttl,
+// or never if ttl is 0.
+func (kv *KV) Set(ctx context.Context, key string, v any, ttl time.Duration) error {
+	var expiresAt *int64
+	if ttl > 0 {
+		at := kv.clock().Now().Add(ttl).UnixMilli()
+		expiresAt = &at
+	}
+
+	_, err := kv.db.ExecContext(ctx, kv.db.Rebind(`
+		INSERT INTO goo_kv (key, value, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`),
+		key, &JSONColumn[any]{V: v}, expiresAt)
+	if err != nil {
+		return fmt.Errorf("kv: set %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Delete deletes the key. It's not an error if the key doesn't exist.
+func (kv *KV) Delete(ctx context.Context, key string) error {
+	_, err := kv.db.ExecContext(ctx, kv.db.Rebind("DELETE FROM goo_kv WHERE key = ?"), key)
+	if err != nil {
+		return fmt.Errorf("kv: delete %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Sweep deletes the expired keys, and returns how many were deleted.
+func (kv *KV) Sweep(ctx context.Context) (int64, error) {
+	res, err := kv.db.ExecContext(ctx, kv.db.Rebind("DELETE FROM goo_kv WHERE expires_at <= ?"), kv.clock().Now().UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("kv: sweep: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+// Run sweeps the expired keys every SweepInterval until ctx is done.
+func (kv *KV) Run(ctx context.Context) error {
+	interval := kv.SweepInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-kv.clock().After(interval):
+		}
+
+		n, err := kv.Sweep(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				kv.log.Error("sweep failed", "error", err.Error())
+			}
+			continue
+		}
+
+		if n > 0 {
+			kv.log.Debug("swept expired keys", "count", n)
+		}
+	}
+}
+
+func (kv *KV) clock() Clock {
+	if kv.Clock == nil {
+		return SystemClock
+	}
+
+	return kv.Clock
+}
@@ -0,0 +1,86 @@
+package goo
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKV(t *testing.T) {
+	assert := assert.New(t)
+
+	db, err := sqlx.Open("sqlite3", filepath.Join(t.TempDir(), "app.db"))
+	assert.NoError(err)
+	defer db.Close()
+
+	_, err = db.Exec(KVSchema)
+	assert.NoError(err)
+
+	kv, err := ProvideKV(db, slog.Default())
+	assert.NoError(err)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	kv.Clock = fixedClock{now: now}
+
+	ctx := context.Background()
+
+	type flags struct {
+		Beta    bool
+		Rollout int
+	}
+
+	var f flags
+	found, err := kv.Get(ctx, "flags", &f)
+	assert.NoError(err)
+	assert.False(found)
+
+	assert.NoError(kv.Set(ctx, "flags", flags{Beta: true, Rollout: 10}, 0))
+	assert.NoError(kv.Set(ctx, "cache:user:1", "alice", time.Minute))
+
+	found, err = kv.Get(ctx, "flags", &f)
+	assert.NoError(err)
+	assert.True(found)
+	assert.Equal(flags{Beta: true, Rollout: 10}, f)
+
+	// set replaces the value
+	assert.NoError(kv.Set(ctx, "flags", flags{Rollout: 50}, 0))
+	found, err = kv.Get(ctx, "flags", &f)
+	assert.NoError(err)
+	assert.True(found)
+	assert.Equal(flags{Rollout: 50}, f)
+
+	var name string
+	found, err = kv.Get(ctx, "cache:user:1", &name)
+	assert.NoError(err)
+	assert.True(found)
+	assert.Equal("alice", name)
+
+	// expired keys are hidden until swept
+	kv.Clock = fixedClock{now: now.Add(2 * time.Minute)}
+	found, err = kv.Get(ctx, "cache:user:1", &name)
+	assert.NoError(err)
+	assert.False(found)
+
+	n, err := kv.Sweep(ctx)
+	assert.NoError(err)
+	assert.EqualValues(1, n)
+
+	assert.NoError(kv.Delete(ctx, "flags"))
+	assert.NoError(kv.Delete(ctx, "missing"))
+
+	var count int
+	assert.NoError(db.Get(&count, "SELECT COUNT(*) FROM goo_kv"))
+	assert.Equal(0, count)
+}
+
+func TestProvideKVDialect(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ProvideKV(sqlx.NewDb(nil, "mysql"), slog.Default())
+	assert.EqualError(err, "kv: unsupported dialect mysql, only sqlite3 and postgres are supported")
+}
@@ -0,0 +1,174 @@
+package goo
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Lifecycle owns the signal handling, shutdown and exit of an app. Unlike
+// ProvideShutdownContext, which shares one process-wide instance, each
+// Lifecycle is independent, so several apps can run in one test binary:
+//
+//	lifecycle := goo.NewLifecycle(log)
+//	lifecycle.Exit = func(code int) { exited <- code }
+//	lifecycle.Start()
+//	defer lifecycle.Stop()
+type Lifecycle struct {
+	*ShutdownContext
+
+	// Signals trigger a graceful shutdown. Defaults to ShutdownSignals. Set it
+	// empty to not handle shutdown signals.
+	Signals []os.Signal
+	// Exit is called with the exit code after the exit functions ran.
+	// Defaults to os.Exit.
+	Exit func(code int)
+
+	sigs     chan os.Signal
+	hups     chan os.Signal
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	hupMu      sync.Mutex
+	hupTrapped bool
+}
+
+// NewLifecycle creates a Lifecycle. It doesn't handle signals until Start.
+func NewLifecycle(log *slog.Logger) *Lifecycle {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	l := &Lifecycle{
+		Signals: ShutdownSignals,
+		Exit:    os.Exit,
+		stop:    make(chan struct{}),
+	}
+
+	l.ShutdownContext = &ShutdownContext{
+		Context: ctx,
+		logger:  log,
+		cancel:  cancel,
+		exit: func(code int) {
+			l.Exit(code)
+		},
+		onReload: l.trapHUP,
+	}
+
+	return l
+}
+
+// Start listens for the shutdown signals, and SIGHUP once a reload function is
+// registered, and exits when the context is done.
+func (l *Lifecycle) Start() {
+	log := l.logger
+
+	l.sigs = make(chan os.Signal, 32)
+	if len(l.Signals) > 0 {
+		// without signals, Notify would relay all of them
+		signal.Notify(l.sigs, l.Signals...)
+	}
+
+	// 3 signals to force an immediate exit
+	go func() {
+		i := 0
+		for {
+			select {
+			case <-l.stop:
+				return
+			case sig := <-l.sigs:
+				if i == 0 {
+					l.signal.Store(sig)
+					l.Shutdown()
+				}
+
+				log.Debug("graceful exit. 3 signals to exit immediately", "signal", sig.String(), "countdown", 3-i)
+
+				i++
+
+				if i == 3 {
+					// stop handling. the next signal will force an exit
+					signal.Stop(l.sigs)
+				}
+			}
+		}
+	}()
+
+	l.hupMu.Lock()
+	l.hups = make(chan os.Signal, 1)
+	l.hupMu.Unlock()
+
+	if l.hasReloadFns() {
+		l.trapHUP()
+	}
+
+	go func() {
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-l.hups:
+				l.Reload()
+			}
+		}
+	}()
+
+	go func() {
+		select {
+		case <-l.stop:
+		case <-l.Done():
+			l.doExit()
+		}
+	}()
+}
+
+// trapHUP starts relaying SIGHUP to the reload functions, if the Lifecycle
+// has started.
+func (l *Lifecycle) trapHUP() {
+	l.hupMu.Lock()
+	defer l.hupMu.Unlock()
+
+	if l.hups == nil || l.hupTrapped {
+		return
+	}
+
+	select {
+	case <-l.stop:
+		return
+	default:
+	}
+
+	signal.Notify(l.hups, syscall.SIGHUP)
+	l.hupTrapped = true
+}
+
+// Deliver handles the signal as if the process received it, e.g. to test the
+// shutdown of an app. It must be called after Start.
+func (l *Lifecycle) Deliver(sig os.Signal) {
+	if sig == syscall.SIGHUP {
+		l.hups <- sig
+		return
+	}
+
+	l.sigs <- sig
+}
+
+// Stop stops handling signals. It doesn't run the exit functions.
+func (l *Lifecycle) Stop() {
+	l.stopOnce.Do(func() {
+		l.hupMu.Lock()
+		defer l.hupMu.Unlock()
+
+		if l.sigs != nil {
+			signal.Stop(l.sigs)
+			signal.Stop(l.hups)
+		}
+
+		close(l.stop)
+	})
+}
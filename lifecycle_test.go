@@ -0,0 +1,72 @@
+//go:build unix
+
+package goo
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycle(t *testing.T) {
+	assert := assert.New(t)
+
+	newLifecycle := func() (*Lifecycle, chan int) {
+		exited := make(chan int, 1)
+
+		l := NewLifecycle(nil)
+		l.Signals = []os.Signal{syscall.SIGUSR1}
+		l.Exit = func(code int) { exited <- code }
+		l.Start()
+		t.Cleanup(l.Stop)
+
+		return l, exited
+	}
+
+	a, exitedA := newLifecycle()
+	b, exitedB := newLifecycle()
+
+	var ran []string
+	a.OnExit(func() error {
+		ran = append(ran, "a")
+		return nil
+	})
+
+	a.Shutdown()
+	assert.Equal(0, <-exitedA)
+	assert.Equal([]string{"a"}, ran)
+	assert.Nil(a.Signal())
+
+	// b is independent of a
+	assert.NoError(b.Err())
+	assert.NoError(b.BlockExit(func() error { return nil }))
+
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	select {
+	case code := <-exitedB:
+		assert.Equal(0, code)
+	case <-time.After(time.Second):
+		t.Fatal("signal did not exit")
+	}
+	assert.Equal(syscall.SIGUSR1, b.Signal())
+	assert.ErrorIs(b.Err(), context.Canceled)
+}
+
+func TestLifecycleTrapsSIGHUPForReload(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewLifecycle(nil)
+	l.Signals = nil
+	l.Start()
+	defer l.Stop()
+
+	// SIGHUP keeps its default action without reload functions
+	assert.False(l.hupTrapped)
+
+	l.OnReload(func(ctx context.Context) error { return nil })
+	assert.True(l.hupTrapped)
+}
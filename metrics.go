@@ -0,0 +1,123 @@
+package goo
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics is a Prometheus registry with the Go and process collectors, and
+// HTTP metrics of the echo server. App code registers its own metrics with
+// Counter and Gauge.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewMetrics creates a registry with the Go, process and HTTP metrics.
+func NewMetrics() *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	m := &Metrics{
+		Registry: registry,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method and route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+	}
+	registry.MustRegister(m.requests, m.duration)
+
+	return m
+}
+
+// ProvideMetrics creates the Metrics, records the HTTP metrics of the echo
+// server, and serves them at /metrics.
+func ProvideMetrics(e *echo.Echo) *Metrics {
+	m := NewMetrics()
+
+	e.Use(m.Middleware())
+	e.GET("/metrics", echo.WrapHandler(m.Handler()))
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the count and latency of requests. Requests are labelled
+// by route pattern rather than path, to keep the label cardinality bounded.
+func (m *Metrics) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			err := next(c)
+
+			code := c.Response().Status
+			var he *echo.HTTPError
+			if errors.As(err, &he) {
+				code = he.Code
+			} else if err != nil {
+				code = http.StatusInternalServerError
+			}
+
+			method := c.Request().Method
+			route := c.Path()
+
+			m.requests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+			m.duration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
+
+// Counter registers a counter, or returns the one registered with the same
+// name and labels.
+func (m *Metrics) Counter(name, help string, labels ...string) *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
+	return register(m.Registry, counter)
+}
+
+// Gauge registers a gauge, or returns the one registered with the same name
+// and labels.
+func (m *Metrics) Gauge(name, help string, labels ...string) *prometheus.GaugeVec {
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labels)
+	return register(m.Registry, gauge)
+}
+
+// register registers the collector, or returns the existing one. It panics on
+// conflicting registrations, like MustRegister.
+func register[T prometheus.Collector](registry *prometheus.Registry, c T) T {
+	err := registry.Register(c)
+
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		return are.ExistingCollector.(T)
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
@@ -0,0 +1,37 @@
+package goo
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	e := echo.New()
+	m := ProvideMetrics(e)
+
+	jobs := m.Counter("jobs_total", "Jobs processed.", "status")
+	assert.Same(jobs, m.Counter("jobs_total", "Jobs processed.", "status"))
+	jobs.WithLabelValues("ok").Add(2)
+
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	serveGet(e, "/users/1")
+	serveGet(e, "/users/2")
+	serveGet(e, "/missing")
+
+	code, body := serveGet(e, "/metrics")
+	assert.Equal(http.StatusOK, code)
+	assert.Contains(body, `http_requests_total{code="200",method="GET",route="/users/:id"} 2`)
+	assert.Contains(body, `http_requests_total{code="404",method="GET",route=""} 1`)
+	assert.Contains(body, `http_request_duration_seconds_count{method="GET",route="/users/:id"} 2`)
+	assert.Contains(body, `jobs_total{status="ok"} 2`)
+	assert.Contains(body, "go_goroutines")
+	assert.Contains(body, "process_cpu_seconds_total")
+}
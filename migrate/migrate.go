@@ -0,0 +1,112 @@
+// Package migrate generates migration files in the naming scheme of
+// golang-migrate, which goo.ProvideMigrate and goo.ProvideEmbbededMigrate run:
+//
+//	20240101120000_create_users.up.sql
+//	20240101120000_create_users.down.sql
+//
+// The version is the UTC time of creation, so migrations created on different
+// branches don't collide.
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// versionFormat is the timestamp layout of the migration versions.
+const versionFormat = "20060102150405"
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Generate creates an empty pair of up and down migration files in dir,
+// creating dir if needed, and returns their paths. The name is lowercased,
+// with runs of other characters than letters and digits replaced by "_".
+func Generate(dir, name string) (up, down string, err error) {
+	return generate(dir, name, time.Now())
+}
+
+func generate(dir, name string, now time.Time) (up, down string, err error) {
+	slug := strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		return "", "", fmt.Errorf("migrate: invalid migration name %q", name)
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return "", "", fmt.Errorf("migrate: %w", err)
+	}
+
+	version := now.UTC().Format(versionFormat)
+
+	existing, err := filepath.Glob(filepath.Join(dir, version+"_*"))
+	if err != nil {
+		return "", "", fmt.Errorf("migrate: %w", err)
+	}
+	if len(existing) > 0 {
+		return "", "", fmt.Errorf("migrate: version %s already exists: %s", version, existing[0])
+	}
+
+	base := filepath.Join(dir, version+"_"+slug)
+	up = base + ".up.sql"
+	down = base + ".down.sql"
+
+	err = createFile(up, fmt.Sprintf("-- %s\n", name))
+	if err != nil {
+		return "", "", err
+	}
+
+	err = createFile(down, fmt.Sprintf("-- revert %s\n", name))
+	if err != nil {
+		return "", "", errors.Join(err, os.Remove(up))
+	}
+
+	return up, down, nil
+}
+
+func createFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	_, err = f.WriteString(content)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	return f.Close()
+}
+
+// GenerateCmd is a go-arg subcommand that generates a migration:
+//
+//	type Args struct {
+//		NewMigration *migrate.GenerateCmd `arg:"subcommand:new-migration" help:"create a migration"`
+//	}
+type GenerateCmd struct {
+	Name string `arg:"positional,required" help:"name of the migration, e.g. create_users"`
+	Dir  string `arg:"--dir" default:"migrations" help:"directory of the migrations"`
+}
+
+// Run generates the migration, and prints the paths of the files.
+func (c *GenerateCmd) Run() error {
+	dir := c.Dir
+	if dir == "" {
+		dir = "migrations"
+	}
+
+	up, down, err := Generate(dir, c.Name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(up)
+	fmt.Println(down)
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := filepath.Join(t.TempDir(), "migrations")
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	up, down, err := generate(dir, "Create Users!", now)
+	assert.NoError(err)
+	assert.Equal(filepath.Join(dir, "20240102030405_create_users.up.sql"), up)
+	assert.Equal(filepath.Join(dir, "20240102030405_create_users.down.sql"), down)
+
+	data, err := os.ReadFile(up)
+	assert.NoError(err)
+	assert.Equal("-- Create Users!\n", string(data))
+	assert.FileExists(down)
+
+	_, _, err = generate(dir, "add email", now)
+	assert.ErrorContains(err, "version 20240102030405 already exists")
+
+	_, _, err = generate(dir, "!!!", now.Add(time.Second))
+	assert.EqualError(err, `migrate: invalid migration name "!!!"`)
+}
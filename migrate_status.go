@@ -0,0 +1,114 @@
+package goo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migration is a migration of the source, and whether it's applied.
+type Migration struct {
+	Version uint
+	Name    string
+	Applied bool
+	// Dirty is true if the migration failed halfway, and needs fixing with
+	// migrate.Force.
+	Dirty bool
+}
+
+// MigrationStatus lists the migrations in the dir of fsys, applied if at or
+// below the current version of m. golang-migrate only records the current
+// version, so there are no timestamps of when migrations ran; for
+// timestamped migration files, Version is the time of creation.
+func MigrationStatus(fsys fs.FS, dir string, m *migrate.Migrate) ([]Migration, error) {
+	current, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		current, dirty, err = 0, false, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("migration status: %w", err)
+	}
+
+	var migrations []Migration
+	err = eachMigration(fsys, dir, func(version uint, name string, _ io.Reader) error {
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			Applied: version < current || version == current && !dirty,
+			Dirty:   version == current && dirty,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("migration status: %w", err)
+	}
+
+	return migrations, nil
+}
+
+// DryRunMigrate writes the SQL of the pending up migrations to w, in the
+// order Up would run them, without running them.
+func DryRunMigrate(fsys fs.FS, dir string, m *migrate.Migrate, w io.Writer) error {
+	migrations, err := MigrationStatus(fsys, dir, m)
+	if err != nil {
+		return err
+	}
+
+	pending := map[uint]bool{}
+	for _, migration := range migrations {
+		pending[migration.Version] = !migration.Applied
+	}
+
+	return eachMigration(fsys, dir, func(version uint, name string, r io.Reader) error {
+		if !pending[version] {
+			return nil
+		}
+
+		fmt.Fprintf(w, "-- migration %d: %s\n", version, name)
+
+		_, err := io.Copy(w, r)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w)
+		return err
+	})
+}
+
+// eachMigration calls fn with the up migrations of the source, in order.
+func eachMigration(fsys fs.FS, dir string, fn func(version uint, name string, r io.Reader) error) error {
+	source, err := iofs.New(fsys, dir)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	for ; err == nil; version, err = source.Next(version) {
+		r, name, readErr := source.ReadUp(version)
+		if errors.Is(readErr, fs.ErrNotExist) {
+			// a down only migration
+			continue
+		}
+		if readErr != nil {
+			return readErr
+		}
+
+		fnErr := fn(version, name, r)
+		r.Close()
+		if fnErr != nil {
+			return fnErr
+		}
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package goo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Timestamps are the creation and update times of a row, embedded in models:
+//
+//	type User struct {
+//		ID   int64
+//		Name string
+//		goo.Timestamps
+//		goo.SoftDelete
+//	}
+//
+// The columns are created_at and updated_at, stored as unix milliseconds.
+type Timestamps struct {
+	CreatedAt TimeColumn `db:"created_at"`
+	UpdatedAt TimeColumn `db:"updated_at"`
+}
+
+// Touch sets UpdatedAt to now, and CreatedAt too if it's not set, before the
+// row is saved.
+func (t *Timestamps) Touch(now time.Time) {
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = TimeColumn{now}
+	}
+	t.UpdatedAt = TimeColumn{now}
+}
+
+// SoftDelete is the deletion time of a row that is kept after it's deleted,
+// embedded in models. The column is deleted_at, NULL until the row is deleted.
+// SoftDeleteTable filters out the deleted rows.
+type SoftDelete struct {
+	DeletedAt NullColumn[TimeColumn] `db:"deleted_at"`
+}
+
+// IsDeleted reports whether the row is soft-deleted.
+func (s SoftDelete) IsDeleted() bool {
+	return s.DeletedAt.Valid
+}
+
+// SoftDeleteTable queries a table of soft-deleted rows, hiding the deleted
+// ones:
+//
+//	users := goo.NewSoftDeleteTable(db, "users")
+//	err := users.Select(ctx, &active, "name LIKE ?", "a%")
+//	n, err := users.Delete(ctx, "id = ?", id)
+//
+// where is a condition of the rows, or "" for all of them.
+type SoftDeleteTable struct {
+	// Clock defaults to SystemClock.
+	Clock Clock
+
+	db    *sqlx.DB
+	table string
+}
+
+// NewSoftDeleteTable creates the SoftDeleteTable of the table, which must have
+// a deleted_at column.
+func NewSoftDeleteTable(db *sqlx.DB, table string) *SoftDeleteTable {
+	return &SoftDeleteTable{db: db, table: table}
+}
+
+// Select selects the rows that aren't deleted into dest, a pointer to a slice.
+func (t *SoftDeleteTable) Select(ctx context.Context, dest any, where string, args ...any) error {
+	query, err := t.query("SELECT * FROM %s WHERE %s", "deleted_at IS NULL", where)
+	if err != nil {
+		return err
+	}
+
+	return t.db.SelectContext(ctx, dest, query, args...)
+}
+
+// Get gets a row that isn't deleted into dest. It returns sql.ErrNoRows if
+// there is none.
+func (t *SoftDeleteTable) Get(ctx context.Context, dest any, where string, args ...any) error {
+	query, err := t.query("SELECT * FROM %s WHERE %s", "deleted_at IS NULL", where)
+	if err != nil {
+		return err
+	}
+
+	return t.db.GetContext(ctx, dest, query, args...)
+}
+
+// SelectDeleted selects the deleted rows into dest, e.g. for a trash view.
+func (t *SoftDeleteTable) SelectDeleted(ctx context.Context, dest any, where string, args ...any) error {
+	query, err := t.query("SELECT * FROM %s WHERE %s", "deleted_at IS NOT NULL", where)
+	if err != nil {
+		return err
+	}
+
+	return t.db.SelectContext(ctx, dest, query, args...)
+}
+
+// Delete soft-deletes the rows, and returns how many were deleted.
+func (t *SoftDeleteTable) Delete(ctx context.Context, where string, args ...any) (int64, error) {
+	query, err := t.query("UPDATE %s SET deleted_at = ? WHERE %s", "deleted_at IS NULL", where)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.exec(ctx, query, append([]any{TimeColumn{t.clock().Now()}}, args...))
+}
+
+// Restore undeletes the rows, and returns how many were restored.
+func (t *SoftDeleteTable) Restore(ctx context.Context, where string, args ...any) (int64, error) {
+	query, err := t.query("UPDATE %s SET deleted_at = NULL WHERE %s", "deleted_at IS NOT NULL", where)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.exec(ctx, query, args)
+}
+
+// Purge deletes the rows that were soft-deleted before the time for good, and
+// returns how many were deleted.
+func (t *SoftDeleteTable) Purge(ctx context.Context, before time.Time) (int64, error) {
+	query, err := t.query("DELETE FROM %s WHERE %s", "deleted_at < ?", "")
+	if err != nil {
+		return 0, err
+	}
+
+	return t.exec(ctx, query, []any{TimeColumn{before}})
+}
+
+// query formats the query of the table, with the where condition and'ed to
+// the deleted_at condition, and rebinds it for the database.
+func (t *SoftDeleteTable) query(format string, deleted string, where string) (string, error) {
+	if !sqlIdentifier.MatchString(t.table) {
+		return "", fmt.Errorf("invalid table name %q", t.table)
+	}
+
+	cond := deleted
+	if where != "" {
+		cond = deleted + " AND (" + where + ")"
+	}
+
+	return t.db.Rebind(fmt.Sprintf(format, t.table, cond)), nil
+}
+
+func (t *SoftDeleteTable) exec(ctx context.Context, query string, args []any) (int64, error) {
+	res, err := t.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", t.table, err)
+	}
+
+	return res.RowsAffected()
+}
+
+func (t *SoftDeleteTable) clock() Clock {
+	if t.Clock == nil {
+		return SystemClock
+	}
+
+	return t.Clock
+}
@@ -0,0 +1,102 @@
+package goo
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type modelUser struct {
+	ID   int64
+	Name string
+	Timestamps
+	SoftDelete
+}
+
+func TestSoftDeleteTable(t *testing.T) {
+	assert := assert.New(t)
+
+	db, err := sqlx.Open("sqlite3", filepath.Join(t.TempDir(), "app.db"))
+	assert.NoError(err)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, created_at INTEGER, updated_at INTEGER, deleted_at INTEGER)")
+	assert.NoError(err)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, name := range []string{"alice", "bob", "carol"} {
+		u := modelUser{Name: name}
+		u.Touch(now)
+		_, err := db.NamedExec("INSERT INTO users (name, created_at, updated_at, deleted_at) VALUES (:name, :created_at, :updated_at, :deleted_at)", u)
+		assert.NoError(err)
+	}
+
+	users := NewSoftDeleteTable(db, "users")
+	users.Clock = fixedClock{now: now.Add(time.Hour)}
+
+	ctx := context.Background()
+
+	n, err := users.Delete(ctx, "name = ?", "bob")
+	assert.NoError(err)
+	assert.EqualValues(1, n)
+
+	// already deleted
+	n, err = users.Delete(ctx, "name = ?", "bob")
+	assert.NoError(err)
+	assert.EqualValues(0, n)
+
+	var active []modelUser
+	assert.NoError(users.Select(ctx, &active, ""))
+	if assert.Len(active, 2) {
+		assert.Equal("alice", active[0].Name)
+		assert.Equal("carol", active[1].Name)
+		assert.Equal(now, active[0].CreatedAt.UTC())
+		assert.False(active[0].IsDeleted())
+	}
+
+	var bob modelUser
+	err = users.Get(ctx, &bob, "name = ?", "bob")
+	assert.ErrorIs(err, sql.ErrNoRows)
+
+	var deleted []modelUser
+	assert.NoError(users.SelectDeleted(ctx, &deleted, ""))
+	if assert.Len(deleted, 1) {
+		assert.True(deleted[0].IsDeleted())
+		assert.Equal(now.Add(time.Hour), deleted[0].DeletedAt.V.UTC())
+	}
+
+	n, err = users.Restore(ctx, "name = ?", "bob")
+	assert.NoError(err)
+	assert.EqualValues(1, n)
+	assert.NoError(users.Get(ctx, &bob, "name = ?", "bob"))
+
+	_, err = users.Delete(ctx, "")
+	assert.NoError(err)
+
+	n, err = users.Purge(ctx, now)
+	assert.NoError(err)
+	assert.EqualValues(0, n)
+
+	n, err = users.Purge(ctx, now.Add(2*time.Hour))
+	assert.NoError(err)
+	assert.EqualValues(3, n)
+}
+
+func TestTimestampsTouch(t *testing.T) {
+	assert := assert.New(t)
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var ts Timestamps
+	ts.Touch(created)
+	ts.Touch(created.Add(time.Minute))
+
+	assert.Equal(created, ts.CreatedAt.Time)
+	assert.Equal(created.Add(time.Minute), ts.UpdatedAt.Time)
+}
@@ -0,0 +1,188 @@
+package goo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// pgListenerPingInterval is how often an idle PGListener pings the server, to
+// detect a dead connection.
+const pgListenerPingInterval = 90 * time.Second
+
+// NotifyHandler handles the payload of a Postgres notification.
+type NotifyHandler func(ctx context.Context, payload string) error
+
+// pgListenerConn is the pq.Listener of a PGListener.
+type pgListenerConn interface {
+	Listen(channel string) error
+	Ping() error
+	Close() error
+	NotificationChannel() <-chan *pq.Notification
+}
+
+// PGListener dispatches the Postgres notifications of the channels it listens
+// to, e.g. for cache invalidation:
+//
+//	listener.Handle("users", func(ctx context.Context, id string) error {
+//		cache.Delete(id)
+//		return nil
+//	})
+//	listener.OnReconnect(func(ctx context.Context) error {
+//		cache.Clear()
+//		return nil
+//	})
+//	group.Go("listener", listener.Run)
+//
+//	err := goo.PGNotify(ctx, db, "users", id)
+//
+// It listens on its own connection, which is reestablished with backoff when
+// lost. Notifications sent while disconnected are lost, so the OnReconnect
+// functions should resync.
+type PGListener struct {
+	conn      pgListenerConn
+	log       *slog.Logger
+	handlers  map[string][]NotifyHandler
+	reconnect []func(ctx context.Context) error
+	closeOnce sync.Once
+}
+
+// ProvidePGListener creates a PGListener of the configured postgres database.
+// Its connection is closed on exit.
+func ProvidePGListener(goocfg *Config, down *ShutdownContext, log *slog.Logger) (*PGListener, error) {
+	if goocfg.Database == nil {
+		return nil, fmt.Errorf("no database configuration")
+	}
+
+	cfg := goocfg.Database
+	if cfg.Dialect != "postgres" && cfg.Dialect != "pgx" {
+		return nil, fmt.Errorf("pg listener: unsupported dialect %s", cfg.Dialect)
+	}
+
+	log = log.With("_type", "PGListener")
+
+	conn := pq.NewListener(cfg.DSN, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventConnected:
+			log.Debug("connected")
+		case pq.ListenerEventDisconnected:
+			log.Warn("disconnected", "error", fmt.Sprint(err))
+		case pq.ListenerEventReconnected:
+			log.Info("reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Warn("connection attempt failed", "error", fmt.Sprint(err))
+		}
+	})
+
+	l := newPGListener(conn, log)
+	down.OnExit(l.close)
+
+	return l, nil
+}
+
+func newPGListener(conn pgListenerConn, log *slog.Logger) *PGListener {
+	return &PGListener{
+		conn:     conn,
+		log:      log,
+		handlers: map[string][]NotifyHandler{},
+	}
+}
+
+// Handle adds a handler of the notifications of the channel. It must be
+// called before Run. The channel name is case-sensitive.
+func (l *PGListener) Handle(channel string, handler NotifyHandler) {
+	l.handlers[channel] = append(l.handlers[channel], handler)
+}
+
+// OnReconnect adds a function called after the connection is reestablished.
+// It must be called before Run.
+func (l *PGListener) OnReconnect(fn func(ctx context.Context) error) {
+	l.reconnect = append(l.reconnect, fn)
+}
+
+// Run listens to the channels of the handlers, and dispatches their
+// notifications until ctx is done. The handlers run one at a time, in the
+// order of the notifications. Handler errors are logged.
+func (l *PGListener) Run(ctx context.Context) error {
+	// unblocks Listen if the server is down
+	stop := context.AfterFunc(ctx, func() { l.close() })
+	defer stop()
+
+	for channel := range l.handlers {
+		err := l.conn.Listen(channel)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("pg listener: listen %s: %w", channel, err)
+		}
+	}
+
+	notifications := l.conn.NotificationChannel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n, ok := <-notifications:
+			if !ok {
+				return nil
+			}
+
+			l.dispatch(ctx, n)
+		case <-time.After(pgListenerPingInterval):
+			go func() {
+				err := l.conn.Ping()
+				if err != nil && ctx.Err() == nil {
+					l.log.Warn("ping failed", "error", err.Error())
+				}
+			}()
+		}
+	}
+}
+
+// dispatch runs the handlers of the notification. pq sends nil after it
+// reconnects.
+func (l *PGListener) dispatch(ctx context.Context, n *pq.Notification) {
+	if n == nil {
+		for _, fn := range l.reconnect {
+			err := fn(ctx)
+			if err != nil {
+				l.log.Error("reconnect function failed", "error", err.Error())
+			}
+		}
+		return
+	}
+
+	for _, handler := range l.handlers[n.Channel] {
+		err := handler(ctx, n.Extra)
+		if err != nil {
+			l.log.Error("notification handler failed", "channel", n.Channel, "error", err.Error())
+		}
+	}
+}
+
+func (l *PGListener) close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		err = l.conn.Close()
+	})
+
+	return err
+}
+
+// PGNotify sends the payload to the listeners of the channel. In a
+// transaction, it's sent when the transaction commits.
+func PGNotify(ctx context.Context, db sqlx.ExecerContext, channel, payload string) error {
+	_, err := db.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+	if err != nil {
+		return fmt.Errorf("pg notify %s: %w", channel, err)
+	}
+
+	return nil
+}
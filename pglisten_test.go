@@ -0,0 +1,86 @@
+package goo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeListenerConn struct {
+	listened []string
+	notify   chan *pq.Notification
+	closed   chan struct{}
+}
+
+func (c *fakeListenerConn) Listen(channel string) error {
+	c.listened = append(c.listened, channel)
+	return nil
+}
+
+func (c *fakeListenerConn) Ping() error {
+	return nil
+}
+
+func (c *fakeListenerConn) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func (c *fakeListenerConn) NotificationChannel() <-chan *pq.Notification {
+	return c.notify
+}
+
+func TestPGListener(t *testing.T) {
+	assert := assert.New(t)
+
+	conn := &fakeListenerConn{notify: make(chan *pq.Notification), closed: make(chan struct{})}
+	l := newPGListener(conn, slog.Default())
+
+	got := make(chan string, 10)
+	l.Handle("users", func(ctx context.Context, payload string) error {
+		got <- "users:" + payload
+		return nil
+	})
+	l.Handle("users", func(ctx context.Context, payload string) error {
+		return errors.New("logged, and doesn't stop the listener")
+	})
+	l.OnReconnect(func(ctx context.Context) error {
+		got <- "reconnected"
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- l.Run(ctx)
+	}()
+
+	conn.notify <- &pq.Notification{Channel: "users", Extra: "1"}
+	conn.notify <- nil
+	conn.notify <- &pq.Notification{Channel: "other", Extra: "ignored"}
+	conn.notify <- &pq.Notification{Channel: "users", Extra: "2"}
+
+	assert.Equal("users:1", <-got)
+	assert.Equal("reconnected", <-got)
+	assert.Equal("users:2", <-got)
+	assert.Equal([]string{"users"}, conn.listened)
+
+	cancel()
+	assert.NoError(<-done)
+
+	// the connection is closed once, on shutdown or exit
+	<-conn.closed
+	assert.NoError(l.close())
+}
+
+func TestProvidePGListenerDialect(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := &Config{Database: &DatabaseConfig{Dialect: "sqlite3", DSN: "app.db"}}
+	_, err := ProvidePGListener(cfg, nil, slog.Default())
+	assert.EqualError(err, "pg listener: unsupported dialect sqlite3")
+}
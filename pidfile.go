@@ -0,0 +1,100 @@
+package goo
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type PIDFileConfig struct {
+	// Path of the PID file, e.g. /run/myapp.pid.
+	Path string
+}
+
+// ErrAlreadyRunning is returned when another process holds the PID file lock.
+var ErrAlreadyRunning = errors.New("already running")
+
+// PIDFile is a PID file holding an exclusive lock, so only one instance of the
+// daemon runs at a time.
+type PIDFile struct {
+	path string
+	file *os.File
+}
+
+// ProvidePIDFile writes the PID file and locks it, if configured. It fails
+// with ErrAlreadyRunning if another process holds the lock. The file is
+// removed on exit.
+func ProvidePIDFile(cfg *Config, ctx *ShutdownContext, log *slog.Logger) (*PIDFile, error) {
+	if cfg.PIDFile == nil || cfg.PIDFile.Path == "" {
+		return &PIDFile{}, nil
+	}
+
+	p, err := LockPIDFile(cfg.PIDFile.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("locked pid file", "path", p.path)
+	ctx.OnExitWithPriority(ExitPhaseClose, p.Close)
+
+	return p, nil
+}
+
+// errLocked is returned by lockFile if another process holds the lock.
+var errLocked = errors.New("file is locked")
+
+// LockPIDFile locks the PID file at path, and writes the pid of the process.
+// Locking is only supported on unix.
+func LockPIDFile(path string) (*PIDFile, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("pid file: %w", err)
+	}
+
+	err = lockFile(f)
+	if err != nil {
+		defer f.Close()
+
+		if errors.Is(err, errLocked) {
+			pid, _ := readPID(f)
+			return nil, fmt.Errorf("pid file %s: %w (pid %d)", path, ErrAlreadyRunning, pid)
+		}
+
+		return nil, fmt.Errorf("pid file: %w", err)
+	}
+
+	err = f.Truncate(0)
+	if err == nil {
+		_, err = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("pid file: %w", err)
+	}
+
+	return &PIDFile{path: path, file: f}, nil
+}
+
+func readPID(f *os.File) (int, error) {
+	buf := make([]byte, 32)
+	n, err := f.ReadAt(buf, 0)
+	if n == 0 {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+}
+
+// Close removes the PID file and releases the lock.
+func (p *PIDFile) Close() error {
+	if p.file == nil {
+		return nil
+	}
+
+	// remove while locked, so a new instance doesn't lock a removed file
+	err := os.Remove(p.path)
+	return errors.Join(err, p.file.Close())
+}
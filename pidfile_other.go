@@ -0,0 +1,14 @@
+//go:build !unix
+
+package goo
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// lockFile fails, as file locks are only supported on unix.
+func lockFile(f *os.File) error {
+	return fmt.Errorf("file locks are not supported on %s", runtime.GOOS)
+}
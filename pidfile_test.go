@@ -0,0 +1,38 @@
+//go:build unix
+
+package goo
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPIDFile(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "app.pid")
+
+	p, err := LockPIDFile(path)
+	assert.NoError(err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal(strconv.Itoa(os.Getpid())+"\n", string(data))
+
+	// flock locks are per open file, so a second lock in the same process fails
+	_, err = LockPIDFile(path)
+	assert.ErrorIs(err, ErrAlreadyRunning)
+	assert.ErrorContains(err, "(pid "+strconv.Itoa(os.Getpid())+")")
+
+	assert.NoError(p.Close())
+	_, err = os.Stat(path)
+	assert.True(os.IsNotExist(err))
+
+	p, err = LockPIDFile(path)
+	assert.NoError(err)
+	assert.NoError(p.Close())
+}
@@ -0,0 +1,124 @@
+package goo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// profilesKey is the top-level config key of the profiles.
+const profilesKey = "profiles"
+
+// decodeConfig decodes the config data, with the named profile, e.g. "prod",
+// merged over the defaults. A config file can describe all the environments
+// of a small project:
+//
+//	database:
+//	  dsn: dev.db
+//	logging:
+//	  loglevel: debug
+//	profiles:
+//	  prod:
+//	    database:
+//	      dsn: /var/lib/app/prod.db
+//
+// Objects are merged recursively, and other values replaced. Without a
+// profile, the profiles are ignored.
+//
+// If the config declares deprecated keys (see ConfigDeprecator), they are
+// migrated before merging.
+func decodeConfig(data []byte, format string, profile string, o any) error {
+	var m map[string]any
+	err := Decode(bytes.NewReader(data), format, &m)
+	if err != nil {
+		// report the error of decoding into the config
+		return Decode(bytes.NewReader(data), format, o)
+	}
+
+	deprecations := configDeprecations(o)
+
+	key, ok := lookupKey(m, profilesKey)
+	if !ok && len(deprecations) == 0 {
+		return Decode(bytes.NewReader(data), format, o)
+	}
+
+	profiles, _ := m[key].(map[string]any)
+	delete(m, key)
+
+	migrateConfig(m, deprecations)
+
+	if ok && profile != "" {
+		overrides, ok := profiles[profile].(map[string]any)
+		if !ok {
+			return fmt.Errorf("config profile %q not found", profile)
+		}
+
+		migrateConfig(overrides, deprecations)
+		mergeConfig(m, overrides)
+	}
+
+	merged, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("decode profile: %w", err)
+	}
+
+	return Decode(bytes.NewReader(merged), JSONFormat, o)
+}
+
+// decodeConfigFile is decodeConfig for a file, with its includes merged in.
+func decodeConfigFile(file string, profile string, o any) error {
+	data, format, err := readConfigFile(file)
+	if err != nil {
+		return err
+	}
+
+	return decodeConfig(data, format, profile, o)
+}
+
+// envProfile returns the profile named by {prefix}ENV, e.g. APP_ENV. Without
+// a prefix, there is no profile, rather than reading the shell's ENV.
+func envProfile(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+
+	return os.Getenv(prefix + "ENV")
+}
+
+// mergeConfig merges src into dst recursively. Keys match case-insensitively,
+// as when decoding into a struct.
+func mergeConfig(dst, src map[string]any) {
+	for k, v := range src {
+		key, ok := lookupKey(dst, k)
+		if !ok {
+			dst[k] = v
+			continue
+		}
+
+		dstMap, dstOK := dst[key].(map[string]any)
+		srcMap, srcOK := v.(map[string]any)
+		if dstOK && srcOK {
+			mergeConfig(dstMap, srcMap)
+			continue
+		}
+
+		dst[key] = v
+	}
+}
+
+// lookupKey finds the key of m that equals k case-insensitively.
+func lookupKey(m map[string]any, k string) (string, bool) {
+	if _, ok := m[k]; ok {
+		return k, true
+	}
+
+	for key := range m {
+		if strings.EqualFold(key, k) {
+			return key, true
+		}
+	}
+
+	return "", false
+}
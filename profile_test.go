@@ -0,0 +1,79 @@
+package goo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigProfiles(t *testing.T) {
+	assert := assert.New(t)
+
+	type AppConfig struct {
+		Config
+
+		Workers int
+		Hosts   []string
+	}
+
+	t.Setenv("APP_CONFIG_YAML", `
+database:
+  dialect: sqlite3
+  dsn: dev.db
+logging:
+  loglevel: debug
+workers: 2
+hosts: [localhost]
+profiles:
+  prod:
+    Database:
+      DSN: /var/lib/app/prod.db
+    workers: 8
+    hosts: [a.example.com, b.example.com]
+`)
+
+	cfg, err := ParseConfig[AppConfig]("app")
+	assert.NoError(err)
+	assert.Equal("dev.db", cfg.Database.DSN)
+	assert.Equal(2, cfg.Workers)
+
+	t.Setenv("APP_ENV", "prod")
+	cfg, err = ParseConfig[AppConfig]("app")
+	assert.NoError(err)
+	assert.Equal(&DatabaseConfig{Dialect: "sqlite3", DSN: "/var/lib/app/prod.db"}, cfg.Database)
+	assert.Equal("debug", cfg.Logging.LogLevel)
+	assert.Equal(8, cfg.Workers)
+	assert.Equal([]string{"a.example.com", "b.example.com"}, cfg.Hosts)
+
+	t.Setenv("APP_ENV", "staging")
+	_, err = ParseConfig[AppConfig]("app")
+	assert.EqualError(err, `config profile "staging" not found`)
+}
+
+func TestConfigProfilesTOML(t *testing.T) {
+	assert := assert.New(t)
+
+	type AppConfig struct {
+		Workers int
+		Tags    map[string]string
+	}
+
+	t.Setenv("APP_ENV", "prod")
+	t.Setenv("APP_CONFIG_TOML", `
+Workers = 2
+
+[Tags]
+team = "core"
+tier = "dev"
+
+[profiles.prod]
+Workers = 8
+
+[profiles.prod.Tags]
+tier = "prod"
+`)
+
+	cfg, err := ParseConfig[AppConfig]("app")
+	assert.NoError(err)
+	assert.Equal(&AppConfig{Workers: 8, Tags: map[string]string{"team": "core", "tier": "prod"}}, cfg)
+}
@@ -0,0 +1,59 @@
+package goo
+
+import (
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"time"
+)
+
+// QueryLogConfig logs the queries of the database.
+type QueryLogConfig struct {
+	// SlowThreshold flags the queries slower than it, logged as warnings.
+	// 0 doesn't flag any.
+	SlowThreshold Duration
+	// RedactArgs logs the number of the query args instead of their values,
+	// e.g. if they may be passwords or personal data.
+	RedactArgs bool
+}
+
+// queryLogHook logs every query at the debug level, with its duration, rows
+// and args, and slow or failed queries as warnings. The duration of a query
+// includes reading its rows.
+func queryLogHook(cfg *QueryLogConfig, log *slog.Logger) queryHook {
+	return func(ctx context.Context, query string, args []driver.NamedValue) (context.Context, queryDone) {
+		start := time.Now()
+
+		return ctx, func(rows int64, err error) {
+			duration := time.Since(start)
+
+			attrs := []any{"query", query, "duration", duration}
+			if rows >= 0 {
+				attrs = append(attrs, "rows", rows)
+			}
+			if cfg.RedactArgs {
+				attrs = append(attrs, "args", len(args))
+			} else {
+				attrs = append(attrs, "args", queryArgValues(args))
+			}
+
+			switch {
+			case err != nil:
+				log.WarnContext(ctx, "query failed", append(attrs, "error", err.Error())...)
+			case cfg.SlowThreshold > 0 && duration >= cfg.SlowThreshold.Std():
+				log.WarnContext(ctx, "slow query", attrs...)
+			default:
+				log.DebugContext(ctx, "query", attrs...)
+			}
+		}
+	}
+}
+
+func queryArgValues(args []driver.NamedValue) []any {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	return values
+}
@@ -0,0 +1,69 @@
+package goo
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryLog(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	cfg := &Config{Database: &DatabaseConfig{
+		Dialect:  "sqlite3",
+		DSN:      filepath.Join(t.TempDir(), "app.db"),
+		QueryLog: &QueryLogConfig{},
+	}}
+
+	c := &ShutdownContext{Context: context.Background(), logger: log}
+	defer c.runExitFns()
+
+	db, err := ProvideSQLX(cfg, c, log)
+	assert.NoError(err)
+
+	_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	assert.NoError(err)
+	_, err = db.Exec("INSERT INTO users (name) VALUES (?)", "alice")
+	assert.NoError(err)
+
+	var name string
+	assert.NoError(db.Get(&name, "SELECT name FROM users WHERE id = ?", 1))
+	assert.Equal("alice", name)
+
+	// prepared statements are logged too
+	stmt, err := db.Preparex("SELECT name FROM users WHERE name = ?")
+	assert.NoError(err)
+	assert.NoError(stmt.Get(&name, "alice"))
+	assert.NoError(stmt.Close())
+
+	_, err = db.Exec("SELECT * FROM missing")
+	assert.Error(err)
+
+	out := buf.String()
+	assert.Contains(out, `level=DEBUG msg=query query="INSERT INTO users (name) VALUES (?)" duration=`)
+	assert.Contains(out, `rows=1 args=[alice]`)
+	assert.Contains(out, `query="SELECT name FROM users WHERE id = ?"`)
+	assert.Contains(out, `query="SELECT name FROM users WHERE name = ?"`)
+	assert.Contains(out, `level=WARN msg="query failed" query="SELECT * FROM missing"`)
+	assert.NotContains(out, "slow query")
+
+	buf.Reset()
+	cfg.Database.QueryLog.RedactArgs = true
+	cfg.Database.QueryLog.SlowThreshold = Duration(time.Nanosecond)
+
+	db, err = ProvideSQLX(cfg, c, log)
+	assert.NoError(err)
+
+	assert.NoError(db.Get(&name, "SELECT name FROM users WHERE id = ?", 1))
+	assert.Contains(buf.String(), `level=WARN msg="slow query" query="SELECT name FROM users WHERE id = ?"`)
+	assert.Contains(buf.String(), `args=1`)
+	assert.NotContains(buf.String(), "alice")
+}
@@ -0,0 +1,366 @@
+package goo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// QueueSchema creates the goo_jobs table of the Queue. Add it to a migration
+// of the app, e.g. migrations/001_jobs.up.sql. run_at is when a ready job is
+// due, or when a running job becomes visible again, in unix milliseconds.
+const QueueSchema = `
+CREATE TABLE IF NOT EXISTS goo_jobs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	job TEXT NOT NULL,
+	payload BLOB NOT NULL,
+	status TEXT NOT NULL DEFAULT 'ready',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	run_at INTEGER NOT NULL,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS goo_jobs_run_at ON goo_jobs (status, run_at);
+`
+
+const (
+	jobStatusReady = "ready"
+	jobStatusDead  = "dead"
+)
+
+// QueueJob is a job of the Queue.
+type QueueJob struct {
+	ID      int64
+	Name    string `db:"job"`
+	Payload []byte
+	// Attempts counts the runs of the job, including the current one.
+	Attempts int
+	// LastError is the error of the last failed run.
+	LastError string `db:"last_error"`
+}
+
+// Decode decodes the JSON payload of the job.
+func (j *QueueJob) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// QueueHandler runs a job of the Queue. A job that returns an error is
+// retried with backoff, and moved to the dead jobs after MaxAttempts.
+type QueueHandler func(ctx context.Context, job *QueueJob) error
+
+// Queue is a job queue stored in the goo_jobs table of a sqlite database, for
+// background work that must survive restarts, without running Redis:
+//
+//	queue.Handle("email", func(ctx context.Context, job *goo.QueueJob) error {
+//		var email Email
+//		if err := job.Decode(&email); err != nil {
+//			return err
+//		}
+//		return send(ctx, email)
+//	})
+//	group.Go("queue", queue.Run)
+//
+//	err := queue.Enqueue(ctx, "email", Email{To: "alice@example.com"})
+//
+// A job that runs longer than VisibilityTimeout, e.g. because the process
+// crashed, is run again, so handlers should be idempotent. With Concurrency
+// above 1, set SQLiteConfig.BusyTimeout so that workers wait for each other's
+// writes.
+type Queue struct {
+	// Concurrency is the number of jobs run at a time. Defaults to 1.
+	Concurrency int
+	// VisibilityTimeout is how long a job may run before it's run again.
+	// Defaults to 5 minutes.
+	VisibilityTimeout time.Duration
+	// MaxAttempts is the number of runs of a failing job before it's dead.
+	// Defaults to 5.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, doubled for each
+	// retry up to an hour. Defaults to 1 second.
+	RetryBackoff time.Duration
+	// PollInterval is how often idle workers check for due jobs. Jobs
+	// enqueued by the process are run right away. Defaults to 1 second.
+	PollInterval time.Duration
+	// Clock defaults to SystemClock.
+	Clock Clock
+
+	db       *sqlx.DB
+	log      *slog.Logger
+	handlers map[string]QueueHandler
+	wake     chan struct{}
+}
+
+// ProvideQueue creates the Queue of the database, which must be sqlite, and
+// have the table of QueueSchema.
+func ProvideQueue(db *sqlx.DB, log *slog.Logger) (*Queue, error) {
+	if db.DriverName() != "sqlite3" {
+		return nil, fmt.Errorf("queue: unsupported dialect %s, only sqlite3 is supported", db.DriverName())
+	}
+
+	return &Queue{
+		db:       db,
+		log:      log.With("_type", "Queue"),
+		handlers: map[string]QueueHandler{},
+		wake:     make(chan struct{}, 1),
+	}, nil
+}
+
+// Handle registers the handler of the jobs named job. It must be called
+// before Run. Jobs without handlers stay in the queue.
+func (q *Queue) Handle(job string, handler QueueHandler) {
+	q.handlers[job] = handler
+}
+
+// Enqueue adds a job with the payload encoded as JSON.
+func (q *Queue) Enqueue(ctx context.Context, job string, payload any) error {
+	return q.EnqueueAt(ctx, job, payload, q.clock().Now())
+}
+
+// EnqueueAt adds a job that runs at or after the time.
+func (q *Queue) EnqueueAt(ctx context.Context, job string, payload any, at time.Time) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("queue: encode %s payload: %w", job, err)
+	}
+
+	_, err = q.db.ExecContext(ctx,
+		"INSERT INTO goo_jobs (job, payload, run_at, created_at) VALUES (?, ?, ?, ?)",
+		job, data, at.UnixMilli(), q.clock().Now().UnixMilli())
+	if err != nil {
+		return fmt.Errorf("queue: enqueue %s: %w", job, err)
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Run runs the jobs until ctx is done, then waits for the running jobs to
+// finish. Job runs get a context that isn't canceled on shutdown, so that
+// they can drain, but is done after VisibilityTimeout.
+func (q *Queue) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	for range max(q.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+
+	wg.Wait()
+	return nil
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := q.claim(ctx)
+		if err != nil && ctx.Err() == nil {
+			q.log.Error("claim job failed", "error", err.Error())
+		}
+
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-q.wake:
+			case <-q.clock().After(q.pollInterval()):
+			}
+			continue
+		}
+
+		q.runJob(ctx, job)
+	}
+}
+
+// claim takes the next due job, hiding it from the other workers for
+// VisibilityTimeout. It returns nil if no job is due.
+func (q *Queue) claim(ctx context.Context) (*QueueJob, error) {
+	if len(q.handlers) == 0 {
+		return nil, nil
+	}
+
+	names := make([]any, 0, len(q.handlers))
+	for name := range q.handlers {
+		names = append(names, name)
+	}
+
+	now := q.clock().Now()
+	args := append([]any{now.Add(q.visibilityTimeout()).UnixMilli(), jobStatusReady, now.UnixMilli()}, names...)
+
+	var job QueueJob
+	err := q.db.GetContext(ctx, &job, `
+		UPDATE goo_jobs SET attempts = attempts + 1, run_at = ?
+		WHERE id = (
+			SELECT id FROM goo_jobs
+			WHERE status = ? AND run_at <= ? AND job IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")+`)
+			ORDER BY run_at, id LIMIT 1
+		)
+		RETURNING id, job, payload, attempts, last_error`, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (q *Queue) runJob(ctx context.Context, job *QueueJob) {
+	log := q.log.With("job", job.Name, "id", job.ID, "attempt", job.Attempts)
+
+	runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), q.visibilityTimeout())
+	defer cancel()
+
+	start := time.Now()
+	log.Debug("job started")
+
+	err := q.runHandler(runCtx, log, job)
+
+	// the job's result is saved even if shutdown began during the run
+	saveCtx := context.WithoutCancel(ctx)
+
+	if err == nil {
+		log.Debug("job finished", "duration", time.Since(start))
+
+		_, err = q.db.ExecContext(saveCtx, "DELETE FROM goo_jobs WHERE id = ?", job.ID)
+		if err != nil {
+			log.Error("delete finished job failed", "error", err.Error())
+		}
+		return
+	}
+
+	if job.Attempts >= q.maxAttempts() {
+		log.Error("job failed, giving up", "error", err.Error(), "duration", time.Since(start))
+
+		_, err = q.db.ExecContext(saveCtx, "UPDATE goo_jobs SET status = ?, last_error = ? WHERE id = ?", jobStatusDead, err.Error(), job.ID)
+		if err != nil {
+			log.Error("save dead job failed", "error", err.Error())
+		}
+		return
+	}
+
+	backoff := q.backoff(job.Attempts)
+	log.Warn("job failed, retrying", "error", err.Error(), "duration", time.Since(start), "backoff", backoff)
+
+	_, err = q.db.ExecContext(saveCtx, "UPDATE goo_jobs SET run_at = ?, last_error = ? WHERE id = ?", q.clock().Now().Add(backoff).UnixMilli(), err.Error(), job.ID)
+	if err != nil {
+		log.Error("save failed job failed", "error", err.Error())
+	}
+}
+
+// runHandler runs the handler of the job, and returns a panic as a
+// PanicError, so that it's a failed attempt rather than a crash.
+func (q *Queue) runHandler(ctx context.Context, log *slog.Logger, job *QueueJob) (err error) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+
+		perr := &PanicError{Value: v, Stack: debug.Stack()}
+		log.Error("job panic", "panic", fmt.Sprint(v), "stack", string(perr.Stack))
+
+		err = perr
+	}()
+
+	return q.handlers[job.Name](ctx, job)
+}
+
+// DeadJobs returns the jobs that failed MaxAttempts times, oldest first.
+func (q *Queue) DeadJobs(ctx context.Context) ([]QueueJob, error) {
+	var jobs []QueueJob
+	err := q.db.SelectContext(ctx, &jobs,
+		"SELECT id, job, payload, attempts, last_error FROM goo_jobs WHERE status = ? ORDER BY id", jobStatusDead)
+	if err != nil {
+		return nil, fmt.Errorf("queue: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// RetryDead moves a dead job back to the queue, with its attempts reset.
+func (q *Queue) RetryDead(ctx context.Context, id int64) error {
+	res, err := q.db.ExecContext(ctx,
+		"UPDATE goo_jobs SET status = ?, attempts = 0, run_at = ? WHERE id = ? AND status = ?",
+		jobStatusReady, q.clock().Now().UnixMilli(), id, jobStatusDead)
+	if err != nil {
+		return fmt.Errorf("queue: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("queue: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("queue: no dead job %d", id)
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (q *Queue) backoff(attempts int) time.Duration {
+	backoff := q.RetryBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for range attempts - 1 {
+		backoff *= 2
+		if backoff >= time.Hour {
+			return time.Hour
+		}
+	}
+
+	return backoff
+}
+
+func (q *Queue) clock() Clock {
+	if q.Clock == nil {
+		return SystemClock
+	}
+
+	return q.Clock
+}
+
+func (q *Queue) visibilityTimeout() time.Duration {
+	if q.VisibilityTimeout <= 0 {
+		return 5 * time.Minute
+	}
+
+	return q.VisibilityTimeout
+}
+
+func (q *Queue) maxAttempts() int {
+	if q.MaxAttempts <= 0 {
+		return 5
+	}
+
+	return q.MaxAttempts
+}
+
+func (q *Queue) pollInterval() time.Duration {
+	if q.PollInterval <= 0 {
+		return time.Second
+	}
+
+	return q.PollInterval
+}
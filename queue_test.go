@@ -0,0 +1,203 @@
+package goo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestQueue(t *testing.T) *Queue {
+	db, err := sqlx.Open("sqlite3", filepath.Join(t.TempDir(), "app.db")+"?_busy_timeout=5000")
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(QueueSchema)
+	assert.NoError(t, err)
+
+	q, err := ProvideQueue(db, slog.Default())
+	assert.NoError(t, err)
+
+	q.PollInterval = 10 * time.Millisecond
+	q.RetryBackoff = time.Millisecond
+
+	return q
+}
+
+func TestQueue(t *testing.T) {
+	assert := assert.New(t)
+
+	q := newTestQueue(t)
+	q.Concurrency = 2
+
+	type email struct{ To string }
+
+	sent := make(chan string, 10)
+	q.Handle("email", func(ctx context.Context, job *QueueJob) error {
+		var e email
+		if err := job.Decode(&e); err != nil {
+			return err
+		}
+		sent <- e.To
+		return nil
+	})
+
+	var attempts atomic.Int32
+	q.MaxAttempts = 3
+	q.Handle("flaky", func(ctx context.Context, job *QueueJob) error {
+		attempts.Add(1)
+		return errors.New("boom")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+
+	assert.NoError(q.Enqueue(ctx, "email", email{To: "alice@example.com"}))
+	assert.NoError(q.Enqueue(ctx, "flaky", nil))
+	// no handler, stays in the queue
+	assert.NoError(q.Enqueue(ctx, "unknown", nil))
+
+	select {
+	case to := <-sent:
+		assert.Equal("alice@example.com", to)
+	case <-time.After(5 * time.Second):
+		t.Fatal("email not sent")
+	}
+
+	var dead []QueueJob
+	assert.Eventually(func() bool {
+		var err error
+		dead, err = q.DeadJobs(ctx)
+		return err == nil && len(dead) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal("flaky", dead[0].Name)
+	assert.Equal(3, dead[0].Attempts)
+	assert.Equal("boom", dead[0].LastError)
+	assert.EqualValues(3, attempts.Load())
+
+	assert.NoError(q.RetryDead(ctx, dead[0].ID))
+	assert.Eventually(func() bool {
+		return attempts.Load() == 6
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	var names []string
+	assert.NoError(q.db.Select(&names, "SELECT job FROM goo_jobs ORDER BY id"))
+	assert.Equal([]string{"flaky", "unknown"}, names)
+}
+
+func TestQueueDrain(t *testing.T) {
+	assert := assert.New(t)
+
+	q := newTestQueue(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	q.Handle("slow", func(ctx context.Context, job *QueueJob) error {
+		close(started)
+		<-release
+		// not canceled by the shutdown
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NoError(q.Enqueue(ctx, "slow", nil))
+
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+		t.Fatal("Run returned before the job finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-done
+
+	var count int
+	assert.NoError(q.db.Get(&count, "SELECT COUNT(*) FROM goo_jobs"))
+	assert.Equal(0, count)
+}
+
+func TestQueueVisibilityTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	q := newTestQueue(t)
+	q.VisibilityTimeout = time.Minute
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	q.Clock = fixedClock{now: now}
+	q.Handle("job", func(ctx context.Context, job *QueueJob) error { return nil })
+
+	ctx := context.Background()
+	assert.NoError(q.Enqueue(ctx, "job", map[string]int{"n": 1}))
+	assert.NoError(q.EnqueueAt(ctx, "job", map[string]int{"n": 2}, now.Add(time.Hour)))
+
+	job, err := q.claim(ctx)
+	assert.NoError(err)
+	assert.Equal(`{"n":1}`, string(job.Payload))
+	assert.Equal(1, job.Attempts)
+
+	// hidden while it runs, and the other isn't due
+	job, err = q.claim(ctx)
+	assert.NoError(err)
+	assert.Nil(job)
+
+	// visible again after the timeout, as if the worker crashed
+	q.Clock = fixedClock{now: now.Add(2 * time.Minute)}
+	job, err = q.claim(ctx)
+	assert.NoError(err)
+	assert.Equal(`{"n":1}`, string(job.Payload))
+	assert.Equal(2, job.Attempts)
+}
+
+func TestQueuePanic(t *testing.T) {
+	assert := assert.New(t)
+
+	q := newTestQueue(t)
+	q.MaxAttempts = 1
+	q.Handle("panics", func(ctx context.Context, job *QueueJob) error {
+		panic("boom")
+	})
+
+	ctx := context.Background()
+	assert.NoError(q.Enqueue(ctx, "panics", nil))
+
+	job, err := q.claim(ctx)
+	assert.NoError(err)
+	q.runJob(ctx, job)
+
+	dead, err := q.DeadJobs(ctx)
+	assert.NoError(err)
+	if assert.Len(dead, 1) {
+		assert.Equal("panic: boom", dead[0].LastError)
+	}
+}
+
+func TestProvideQueueDialect(t *testing.T) {
+	assert := assert.New(t)
+
+	db := sqlx.NewDb(nil, "postgres")
+	_, err := ProvideQueue(db, slog.Default())
+	assert.EqualError(err, "queue: unsupported dialect postgres, only sqlite3 is supported")
+}
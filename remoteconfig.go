@@ -0,0 +1,98 @@
+package goo
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// remoteConfigAttempts is how many times a remote config is fetched before
+// falling back to the cache.
+const remoteConfigAttempts = 3
+
+// remoteConfigBackoff is the delay before the first retry, doubled for each
+// retry.
+var remoteConfigBackoff = 500 * time.Millisecond
+
+// decodeRemoteConfig decodes the config at {prefix}CONFIG_URL, an http(s) URL
+// or s3 URI. Requests send a bearer token from {prefix}CONFIG_URL_TOKEN, or
+// basic auth from {prefix}CONFIG_URL_USERNAME and {prefix}CONFIG_URL_PASSWORD.
+//
+// A config that decodes is cached at {prefix}CONFIG_CACHE, by default in the
+// user cache directory. If all the attempts to fetch the config fail, the
+// last known good config in the cache is used instead.
+func decodeRemoteConfig(prefix string, configURL string, o any) error {
+	log := slog.Default().With("url", configURL)
+	format := urlFormat(configURL)
+
+	header := http.Header{}
+	if token := os.Getenv(prefix + "CONFIG_URL_TOKEN"); token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	} else if username := os.Getenv(prefix + "CONFIG_URL_USERNAME"); username != "" {
+		auth := username + ":" + os.Getenv(prefix+"CONFIG_URL_PASSWORD")
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)))
+	}
+
+	cacheFile := os.Getenv(prefix + "CONFIG_CACHE")
+	if cacheFile == "" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			sum := sha256.Sum256([]byte(configURL))
+			cacheFile = filepath.Join(dir, "goo", "config-"+hex.EncodeToString(sum[:8])+"."+format)
+		}
+	}
+
+	var data []byte
+	var err error
+
+	backoff := remoteConfigBackoff
+	for attempt := 1; attempt <= remoteConfigAttempts; attempt++ {
+		data, err = readURL(configURL, header)
+		if err == nil {
+			break
+		}
+
+		if attempt < remoteConfigAttempts {
+			log.Warn("fetch config failed, retrying", "attempt", attempt, "error", err.Error())
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	if err != nil {
+		if cacheFile == "" {
+			return fmt.Errorf("fetch config: %w", err)
+		}
+
+		cached, cacheErr := os.ReadFile(cacheFile)
+		if cacheErr != nil {
+			return fmt.Errorf("fetch config: %w", err)
+		}
+
+		log.Warn("fetch config failed, using cached config", "cache", cacheFile, "error", err.Error())
+		return decodeConfig(cached, format, envProfile(prefix), o)
+	}
+
+	err = decodeConfig(data, format, envProfile(prefix), o)
+	if err != nil {
+		return err
+	}
+
+	if cacheFile != "" {
+		// the config may hold secrets
+		err = os.MkdirAll(filepath.Dir(cacheFile), 0o700)
+		if err == nil {
+			err = os.WriteFile(cacheFile, data, 0o600)
+		}
+		if err != nil {
+			log.Warn("cache config failed", "cache", cacheFile, "error", err.Error())
+		}
+	}
+
+	return nil
+}
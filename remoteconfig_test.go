@@ -0,0 +1,97 @@
+package goo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfigURL(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func(backoff time.Duration) { remoteConfigBackoff = backoff }(remoteConfigBackoff)
+	remoteConfigBackoff = time.Millisecond
+
+	type AppConfig struct {
+		Workers int
+	}
+
+	var requests int
+	var down bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// the first request of each parse fails
+		if down || requests%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`{"Workers": 4}`))
+	}))
+	defer server.Close()
+
+	cacheFile := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv("APP_CONFIG_URL", server.URL+"/config.json")
+	t.Setenv("APP_CONFIG_URL_TOKEN", "token")
+	t.Setenv("APP_CONFIG_CACHE", cacheFile)
+
+	cfg, err := ParseConfig[AppConfig]("app")
+	assert.NoError(err)
+	assert.Equal(4, cfg.Workers)
+	assert.Equal(2, requests)
+	assert.FileExists(cacheFile)
+
+	// falls back to the last known good config
+	down = true
+	requests = 0
+	cfg, err = ParseConfig[AppConfig]("app")
+	assert.NoError(err)
+	assert.Equal(4, cfg.Workers)
+	assert.Equal(remoteConfigAttempts, requests)
+
+	assert.NoError(os.Remove(cacheFile))
+	_, err = ParseConfig[AppConfig]("app")
+	assert.EqualError(err, "fetch config: http get: non-200 status code: 503")
+}
+
+func TestParseConfigURLTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func(backoff time.Duration) { remoteConfigBackoff = backoff }(remoteConfigBackoff)
+	remoteConfigBackoff = time.Millisecond
+	defer func(client *http.Client) { remoteClient = client }(remoteClient)
+	remoteClient = &http.Client{Timeout: 50 * time.Millisecond}
+
+	type AppConfig struct {
+		Workers int
+	}
+
+	hang := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hang
+	}))
+	defer server.Close()
+	defer close(hang)
+
+	cacheFile := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(os.WriteFile(cacheFile, []byte(`{"Workers": 4}`), 0o644))
+
+	t.Setenv("APP_CONFIG_URL", server.URL+"/config.json")
+	t.Setenv("APP_CONFIG_CACHE", cacheFile)
+
+	// the hanging endpoint times out, and the cached config is used
+	cfg, err := ParseConfig[AppConfig]("app")
+	assert.NoError(err)
+	assert.Equal(4, cfg.Workers)
+}
@@ -1,27 +1,54 @@
 package goo
 
 import (
+	"errors"
+	"fmt"
 	"log"
-
-	"github.com/alexflint/go-arg"
+	"log/slog"
+	"os"
+	"runtime/debug"
 )
 
+// ExitCodePanic is the exit code of Main when the runner panics.
+const ExitCodePanic = 70
+
+// Runner is the entrypoint of an app, run by Main or Run with the parsed
+// command line arguments:
+//
+//	type App struct{ ... }
+//
+//	func (a *App) Run(args *Args) error { ... }
+//
+//	func main() {
+//		var args Args
+//		goo.Main(InitApp, &args)
+//	}
 type Runner[Arg any] interface {
 	Run(arg *Arg) error
 }
 
+// Run initializes the runner with init (usually a wire injector), parses the
+// arguments, boots the ShutdownContext, and runs the runner. On success, it
+// exits gracefully with GracefulExit.
 func Run[T Runner[Arg], Arg any](init func() (T, error), args *Arg) error {
 	r, err := init()
 	if err != nil {
 		return err
 	}
 
-	err = arg.Parse(args)
+	err = parseArgs(os.Args[1:], args)
 	if err != nil {
 		return err
 	}
 
-	err = r.Run(args)
+	if exitCtx != nil {
+		err = exitCtx.Boot()
+		if err != nil {
+			return err
+		}
+	}
+
+	err = runRecover(r, args)
 	if err != nil {
 		return err
 	}
@@ -31,9 +58,58 @@ func Run[T Runner[Arg], Arg any](init func() (T, error), args *Arg) error {
 	return nil
 }
 
+// PanicError is a panic recovered from a runner.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// runRecover runs the runner, and returns a panic as an ExitError with
+// ExitCodePanic.
+func runRecover[Arg any](r Runner[Arg], args *Arg) (err error) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+
+		perr := &PanicError{Value: v, Stack: debug.Stack()}
+
+		log := slog.Default()
+		if exitCtx != nil {
+			log = exitCtx.logger
+		}
+		log.Error("runner panic", "panic", fmt.Sprint(v), "stack", string(perr.Stack))
+
+		err = &ExitError{Code: ExitCodePanic, Err: perr}
+	}()
+
+	return r.Run(args)
+}
+
+// Main runs the runner, and exits with the ExitCode of the error if it fails.
+// Argument errors print the usage and exit with code 2, --help and --version
+// print and exit with code 0, and panics exit with ExitCodePanic. The exit
+// functions run before exiting on error.
 func Main[T Runner[Arg], Arg any](init func() (T, error), args *Arg) {
 	err := Run(init, args)
 	if err != nil {
-		log.Fatalln(err)
+		var argsErr *ArgsError
+		if errors.As(err, &argsErr) {
+			argsErr.Print()
+		} else {
+			log.Println(err)
+		}
+
+		if exitCtx != nil {
+			exitCtx.ShutdownWithError(err)
+			exitCtx.doExit()
+		}
+
+		os.Exit(ExitCode(err))
 	}
 }
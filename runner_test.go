@@ -0,0 +1,27 @@
+package goo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type panicRunner struct{}
+
+func (panicRunner) Run(arg *struct{}) error {
+	var m map[string]int
+	m["boom"] = 1
+	return nil
+}
+
+func TestRunRecover(t *testing.T) {
+	assert := assert.New(t)
+
+	err := runRecover[struct{}](panicRunner{}, &struct{}{})
+	assert.Equal(ExitCodePanic, ExitCode(err))
+
+	var perr *PanicError
+	assert.ErrorAs(err, &perr)
+	assert.Equal("panic: assignment to entry in nil map", perr.Error())
+	assert.Contains(string(perr.Stack), "panicRunner.Run")
+}
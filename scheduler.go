@@ -0,0 +1,154 @@
+package goo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Job is a task run by the Scheduler.
+type Job struct {
+	Name string
+	// Schedule is a standard 5-field cron expression, or a descriptor like
+	// "@hourly" or "@every 10m".
+	Schedule string
+	// Interval runs the job at a fixed interval, instead of Schedule.
+	Interval time.Duration
+	// Timeout bounds each run of the job.
+	Timeout time.Duration
+
+	Run func(ctx context.Context) error
+}
+
+// schedule returns the times the job runs at.
+func (j Job) schedule() (cron.Schedule, error) {
+	switch {
+	case j.Interval > 0:
+		// not cron.Every, which rounds to seconds
+		return intervalSchedule(j.Interval), nil
+	case j.Schedule != "":
+		return cron.ParseStandard(j.Schedule)
+	default:
+		return nil, errors.New("no Schedule or Interval")
+	}
+}
+
+// intervalSchedule runs at a fixed interval from the previous run.
+type intervalSchedule time.Duration
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+// Scheduler runs jobs on cron schedules or fixed intervals. A job never
+// overlaps with itself: a run that is due while the previous run is still
+// going is skipped.
+//
+// Run it as a service of the RunnerGroup, so it stops cleanly on shutdown:
+//
+//	group.Go("scheduler", scheduler.Run)
+type Scheduler struct {
+	// Clock defaults to SystemClock.
+	Clock Clock
+
+	log  *slog.Logger
+	jobs []scheduledJob
+}
+
+type scheduledJob struct {
+	Job
+	schedule cron.Schedule
+}
+
+// ProvideScheduler creates a Scheduler of the jobs provided by the app.
+func ProvideScheduler(log *slog.Logger, jobs []Job) (*Scheduler, error) {
+	s := &Scheduler{log: log.With("_type", "Scheduler")}
+
+	for _, job := range jobs {
+		err := s.Add(job)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Add adds a job. It must be called before Run.
+func (s *Scheduler) Add(job Job) error {
+	schedule, err := job.schedule()
+	if err != nil {
+		return fmt.Errorf("scheduler: job %s: %w", job.Name, err)
+	}
+
+	s.jobs = append(s.jobs, scheduledJob{Job: job, schedule: schedule})
+	return nil
+}
+
+// Run runs the jobs until ctx is done, then waits for the running jobs to
+// return. Job runs get a context that is done on shutdown.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}()
+	}
+
+	wg.Wait()
+	return nil
+}
+
+func (s *Scheduler) loop(ctx context.Context, job scheduledJob) {
+	log := s.log.With("job", job.Name)
+
+	for {
+		// computed after the previous run, so due times during the run are
+		// skipped instead of overlapping
+		now := s.clock().Now()
+		next := job.schedule.Next(now)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock().After(next.Sub(now)):
+		}
+
+		s.runJob(ctx, log, job)
+	}
+}
+
+func (s *Scheduler) clock() Clock {
+	if s.Clock == nil {
+		return SystemClock
+	}
+
+	return s.Clock
+}
+
+func (s *Scheduler) runJob(ctx context.Context, log *slog.Logger, job scheduledJob) {
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	log.Debug("job started")
+
+	err := job.Run(ctx)
+	if err != nil {
+		log.Error("job failed", "error", err.Error(), "duration", time.Since(start))
+		return
+	}
+
+	log.Debug("job finished", "duration", time.Since(start))
+}
@@ -0,0 +1,76 @@
+package goo
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler(t *testing.T) {
+	assert := assert.New(t)
+
+	var fast, slow, overlap, timedOut int32
+	var running int32
+
+	s, err := ProvideScheduler(slog.Default(), []Job{
+		{
+			Name:     "fast",
+			Interval: 10 * time.Millisecond,
+			Run: func(ctx context.Context) error {
+				atomic.AddInt32(&fast, 1)
+				return nil
+			},
+		},
+		{
+			Name:     "slow",
+			Interval: 10 * time.Millisecond,
+			Timeout:  25 * time.Millisecond,
+			Run: func(ctx context.Context) error {
+				if atomic.AddInt32(&running, 1) > 1 {
+					atomic.AddInt32(&overlap, 1)
+				}
+				defer atomic.AddInt32(&running, -1)
+
+				atomic.AddInt32(&slow, 1)
+				<-ctx.Done()
+				if ctx.Err() == context.DeadlineExceeded {
+					atomic.AddInt32(&timedOut, 1)
+				}
+				return ctx.Err()
+			},
+		},
+	})
+	assert.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(s.Run(ctx))
+
+	assert.GreaterOrEqual(fast, int32(5))
+	assert.GreaterOrEqual(slow, int32(2))
+	assert.Less(slow, fast)
+	assert.Equal(int32(0), overlap)
+	assert.GreaterOrEqual(timedOut, int32(2))
+	// Run returned after the running jobs
+	assert.Equal(int32(0), atomic.LoadInt32(&running))
+}
+
+func TestSchedulerInvalidJob(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ProvideScheduler(slog.Default(), []Job{{Name: "bad", Schedule: "not a cron"}})
+	assert.ErrorContains(err, "scheduler: job bad:")
+
+	_, err = ProvideScheduler(slog.Default(), []Job{{Name: "none"}})
+	assert.EqualError(err, "scheduler: job none: no Schedule or Interval")
+
+	s, err := ProvideScheduler(slog.Default(), []Job{{Name: "hourly", Schedule: "0 * * * *"}})
+	assert.NoError(err)
+	next := s.jobs[0].schedule.Next(time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC))
+	assert.Equal(time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC), next)
+}
@@ -0,0 +1,96 @@
+package goo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// secretFilePrefix marks a config value read from a file, e.g.
+// file:///run/secrets/db_password.
+const secretFilePrefix = "file://"
+
+// resolveSecrets replaces the string fields of the struct pointed to by o that
+// reference a file, e.g. "file:///run/secrets/db_password", with the trimmed
+// content of the file. An empty field tagged `secret_file:"path"` is read
+// from path if the file exists, e.g. the Docker secret mount.
+//
+// Fields tagged `secret:"-"` keep file:// values as they are, e.g. the sqlite
+// DSN file:///var/lib/app.db.
+func resolveSecrets(o any) error {
+	return resolveSecretsValue("", reflect.ValueOf(o))
+}
+
+func resolveSecretsValue(path string, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return resolveSecretsValue(path, v.Elem())
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldPath := field.Name
+			switch {
+			case field.Anonymous:
+				fieldPath = path
+			case path != "":
+				fieldPath = path + "." + field.Name
+			}
+
+			fv := v.Field(i)
+			if fv.Kind() == reflect.String {
+				if field.Tag.Get("secret") == "-" {
+					continue
+				}
+
+				err := resolveSecretField(fieldPath, fv, field.Tag.Get("secret_file"))
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			err := resolveSecretsValue(fieldPath, fv)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func resolveSecretField(path string, v reflect.Value, defaultFile string) error {
+	s := v.String()
+
+	var file string
+	switch {
+	case strings.HasPrefix(s, secretFilePrefix):
+		file = strings.TrimPrefix(s, secretFilePrefix)
+	case s == "" && defaultFile != "":
+		if _, err := os.Stat(defaultFile); errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		file = defaultFile
+	default:
+		return nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("secret %s: %w", path, err)
+	}
+
+	v.SetString(strings.TrimSpace(string(data)))
+	return nil
+}
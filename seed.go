@@ -0,0 +1,293 @@
+package goo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Fixture is the rows of a table, loaded by a Seeder from a YAML or JSON file:
+//
+//	# fixtures/posts.yaml
+//	depends: [users]
+//	rows:
+//	  - id: 1
+//	    user_id: 1
+//	    title: hello
+//	    tags: [intro]
+//
+// Objects and arrays are stored as JSON, e.g. for a JSONColumn.
+type Fixture struct {
+	// Table defaults to the name of the file, e.g. "posts".
+	Table string
+	// Depends are the tables seeded before this one, e.g. those referenced by
+	// foreign keys.
+	Depends []string
+	// Key are the columns that identify a row. A row whose key exists is
+	// updated instead of inserted, so that seeding again is idempotent.
+	// Defaults to "id".
+	Key []string
+	// Rows maps the columns to the values.
+	Rows []map[string]any
+}
+
+// Seeder loads the fixtures in a directory of FS into the database, e.g. to
+// fill a development database, or the database of a test, after migrating it:
+//
+//	//go:embed fixtures
+//	var fixtures embed.FS
+//
+//	err := goo.NewSeeder(db, fixtures, "fixtures").Seed(ctx)
+type Seeder struct {
+	DB  *sqlx.DB
+	FS  fs.FS
+	Dir string
+}
+
+// NewSeeder creates a Seeder of the *.yaml, *.yml, *.json and *.jsonc files in
+// the directory of fsys.
+func NewSeeder(db *sqlx.DB, fsys fs.FS, dir string) *Seeder {
+	return &Seeder{DB: db, FS: fsys, Dir: dir}
+}
+
+// Seed inserts or updates the rows of the fixtures in one transaction. The
+// tables are seeded after those they depend on, and otherwise by name.
+func (s *Seeder) Seed(ctx context.Context) error {
+	fixtures, err := s.Fixtures()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("seed: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, f := range fixtures {
+		err := seedFixture(ctx, tx, f)
+		if err != nil {
+			return fmt.Errorf("seed %s: %w", f.Table, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("seed: %w", err)
+	}
+
+	return nil
+}
+
+// Fixtures loads the fixtures, in the order they are seeded.
+func (s *Seeder) Fixtures() ([]*Fixture, error) {
+	entries, err := fs.ReadDir(s.FS, s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("seed: %w", err)
+	}
+
+	var fixtures []*Fixture
+	for _, entry := range entries {
+		format := fixtureFormat(entry.Name())
+		if entry.IsDir() || format == "" {
+			continue
+		}
+
+		file := path.Join(s.Dir, entry.Name())
+		data, err := fs.ReadFile(s.FS, file)
+		if err != nil {
+			return nil, fmt.Errorf("seed: %w", err)
+		}
+
+		var f Fixture
+		err = Decode(bytes.NewReader(data), format, &f)
+		if err != nil {
+			return nil, fmt.Errorf("seed %s: %w", file, err)
+		}
+
+		if f.Table == "" {
+			f.Table = strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		}
+		if len(f.Key) == 0 {
+			f.Key = []string{"id"}
+		}
+
+		fixtures = append(fixtures, &f)
+	}
+
+	return sortFixtures(fixtures)
+}
+
+func fixtureFormat(name string) string {
+	switch format := fileFormat(name); format {
+	case "yml":
+		return YAMLFormat
+	case YAMLFormat, JSONFormat, JSONCFormat:
+		return format
+	default:
+		return ""
+	}
+}
+
+// sortFixtures sorts the fixtures by name, then after their dependencies.
+func sortFixtures(fixtures []*Fixture) ([]*Fixture, error) {
+	sort.Slice(fixtures, func(i, j int) bool {
+		return fixtures[i].Table < fixtures[j].Table
+	})
+
+	byTable := map[string]*Fixture{}
+	for _, f := range fixtures {
+		if byTable[f.Table] != nil {
+			return nil, fmt.Errorf("seed: duplicate fixtures of table %s", f.Table)
+		}
+		byTable[f.Table] = f
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	state := map[string]int{}
+	var sorted []*Fixture
+
+	var visit func(f *Fixture, chain []string) error
+	visit = func(f *Fixture, chain []string) error {
+		chain = append(chain, f.Table)
+
+		switch state[f.Table] {
+		case visiting:
+			return fmt.Errorf("seed: dependency cycle: %s", strings.Join(chain, " -> "))
+		case visited:
+			return nil
+		}
+
+		state[f.Table] = visiting
+		for _, dep := range f.Depends {
+			// a table without fixtures may be seeded by a migration
+			if depFixture, ok := byTable[dep]; ok {
+				err := visit(depFixture, chain)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		state[f.Table] = visited
+
+		sorted = append(sorted, f)
+		return nil
+	}
+
+	for _, f := range fixtures {
+		err := visit(f, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+// sqlIdentifier matches the table and column names, as they're interpolated
+// into the queries.
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+func seedFixture(ctx context.Context, tx *sqlx.Tx, f *Fixture) error {
+	if !sqlIdentifier.MatchString(f.Table) {
+		return fmt.Errorf("invalid table name %q", f.Table)
+	}
+
+	for i, row := range f.Rows {
+		columns := make([]string, 0, len(row))
+		for column := range row {
+			if !sqlIdentifier.MatchString(column) {
+				return fmt.Errorf("row %d: invalid column name %q", i, column)
+			}
+			columns = append(columns, column)
+		}
+		slices.Sort(columns)
+
+		var where []string
+		var keyArgs []any
+		for _, key := range f.Key {
+			v, ok := row[key]
+			if !ok {
+				return fmt.Errorf("row %d: missing key column %s", i, key)
+			}
+
+			where = append(where, key+" = ?")
+			keyArgs = append(keyArgs, seedValue(v))
+		}
+
+		var exists int
+		err := tx.GetContext(ctx, &exists, tx.Rebind(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", f.Table, strings.Join(where, " AND "))), keyArgs...)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+
+		var query string
+		var args []any
+		if exists > 0 {
+			var set []string
+			for _, column := range columns {
+				if slices.Contains(f.Key, column) {
+					continue
+				}
+
+				set = append(set, column+" = ?")
+				args = append(args, seedValue(row[column]))
+			}
+
+			if len(set) == 0 {
+				continue
+			}
+
+			query = fmt.Sprintf("UPDATE %s SET %s WHERE %s", f.Table, strings.Join(set, ", "), strings.Join(where, " AND "))
+			args = append(args, keyArgs...)
+		} else {
+			for _, column := range columns {
+				args = append(args, seedValue(row[column]))
+			}
+
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+			query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", f.Table, strings.Join(columns, ", "), placeholders)
+		}
+
+		_, err = tx.ExecContext(ctx, tx.Rebind(query), args...)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// seedValue converts a decoded value to a query arg: whole numbers to int64,
+// as JSON decodes all numbers as float64, and objects and arrays to JSON.
+func seedValue(v any) any {
+	switch v := v.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+		return v
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+		return string(data)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,103 @@
+package goo
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeeder(t *testing.T) {
+	assert := assert.New(t)
+
+	db, err := sqlx.Open("sqlite3", filepath.Join(t.TempDir(), "app.db")+"?_foreign_keys=on")
+	assert.NoError(err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id), title TEXT, tags TEXT);
+	`)
+	assert.NoError(err)
+
+	fixtures := fstest.MapFS{
+		// seeded after users, despite the name
+		"fixtures/a_posts.yaml": {Data: []byte(`
+table: posts
+depends: [users]
+rows:
+  - id: 1
+    user_id: 1
+    title: hello
+    tags: [intro]
+`)},
+		"fixtures/users.json": {Data: []byte(`{
+			// comments are allowed in fixtures
+			"rows": [{"id": 1, "name": "alice"}, {"id": 2, "name": "bob"}]
+		}`)},
+		"fixtures/README.md": {Data: []byte("ignored")},
+	}
+
+	seeder := NewSeeder(db, fixtures, "fixtures")
+
+	loaded, err := seeder.Fixtures()
+	assert.NoError(err)
+	if assert.Len(loaded, 2) {
+		assert.Equal("users", loaded[0].Table)
+		assert.Equal("posts", loaded[1].Table)
+	}
+
+	ctx := context.Background()
+	assert.NoError(seeder.Seed(ctx))
+
+	var names []string
+	assert.NoError(db.Select(&names, "SELECT name FROM users ORDER BY id"))
+	assert.Equal([]string{"alice", "bob"}, names)
+
+	var tags string
+	assert.NoError(db.Get(&tags, "SELECT tags FROM posts WHERE id = 1"))
+	assert.Equal(`["intro"]`, tags)
+
+	// seeding again updates the rows
+	fixtures["fixtures/users.json"] = &fstest.MapFile{Data: []byte(`{"rows": [{"id": 1, "name": "alice smith"}]}`)}
+	assert.NoError(seeder.Seed(ctx))
+
+	names = nil
+	assert.NoError(db.Select(&names, "SELECT name FROM users ORDER BY id"))
+	assert.Equal([]string{"alice smith", "bob"}, names)
+
+	var count int
+	assert.NoError(db.Get(&count, "SELECT COUNT(*) FROM posts"))
+	assert.Equal(1, count)
+}
+
+func TestSeederErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	cycle := fstest.MapFS{
+		"fixtures/a.yaml": {Data: []byte("depends: [b]")},
+		"fixtures/b.yaml": {Data: []byte("depends: [a]")},
+	}
+	_, err := NewSeeder(nil, cycle, "fixtures").Fixtures()
+	assert.EqualError(err, "seed: dependency cycle: a -> b -> a")
+
+	db, err := sqlx.Open("sqlite3", filepath.Join(t.TempDir(), "app.db"))
+	assert.NoError(err)
+	defer db.Close()
+
+	_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	assert.NoError(err)
+
+	invalid := fstest.MapFS{
+		"fixtures/users.yaml": {Data: []byte(`
+rows:
+  - id: 1
+    "name; DROP TABLE users": x
+`)},
+	}
+	err = NewSeeder(db, invalid, "fixtures").Seed(context.Background())
+	assert.ErrorContains(err, `seed users: row 0: invalid column name "name; DROP TABLE users"`)
+}
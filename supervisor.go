@@ -0,0 +1,83 @@
+package goo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+)
+
+// RestartPolicy is how Supervise restarts a failed service.
+type RestartPolicy struct {
+	// MaxRestarts is the restart budget. After that many restarts, the next
+	// failure is returned. 0 means unlimited.
+	MaxRestarts int
+	// MinBackoff is the delay before the first restart. Defaults to 1 second.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay, which doubles with every restart. Defaults to
+	// 1 minute.
+	MaxBackoff time.Duration
+	// Clock times the backoff. Defaults to SystemClock.
+	Clock Clock
+}
+
+// Supervise returns a service that runs run, and restarts it with exponential
+// backoff when it fails or panics. It stops when run returns nil, ctx is done,
+// or the restart budget runs out:
+//
+//	group.Go("consumer", goo.Supervise("consumer", consumer.Run, goo.RestartPolicy{MaxRestarts: 5}, log))
+func Supervise(name string, run func(ctx context.Context) error, policy RestartPolicy, log *slog.Logger) func(ctx context.Context) error {
+	log = log.With("_type", "Supervisor", "name", name)
+
+	minBackoff := policy.MinBackoff
+	if minBackoff <= 0 {
+		minBackoff = time.Second
+	}
+
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = time.Minute
+	}
+
+	clock := policy.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	return func(ctx context.Context) error {
+		backoff := minBackoff
+		for restarts := 0; ; restarts++ {
+			err := runSupervised(ctx, run)
+			if err == nil || ctx.Err() != nil {
+				return nil
+			}
+
+			if policy.MaxRestarts > 0 && restarts >= policy.MaxRestarts {
+				log.Error("restart budget exhausted", "restarts", restarts, "error", err.Error())
+				return fmt.Errorf("%s: gave up after %d restarts: %w", name, restarts, err)
+			}
+
+			log.Warn("restarting", "restart", restarts+1, "backoff", backoff, "error", err.Error())
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-clock.After(backoff):
+			}
+
+			backoff = min(backoff*2, maxBackoff)
+		}
+	}
+}
+
+// runSupervised runs run, and returns a panic as a PanicError.
+func runSupervised(ctx context.Context, run func(ctx context.Context) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return run(ctx)
+}
@@ -0,0 +1,56 @@
+package goo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupervise(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := RestartPolicy{MaxRestarts: 3, MinBackoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+
+	t.Run("recovers", func(t *testing.T) {
+		var runs int
+		run := Supervise("flaky", func(ctx context.Context) error {
+			runs++
+			switch runs {
+			case 1:
+				return errors.New("connection lost")
+			case 2:
+				panic("boom")
+			}
+			return nil
+		}, policy, slog.Default())
+
+		assert.NoError(run(context.Background()))
+		assert.Equal(3, runs)
+	})
+
+	t.Run("budget", func(t *testing.T) {
+		var runs int
+		run := Supervise("broken", func(ctx context.Context) error {
+			runs++
+			return errors.New("connection refused")
+		}, policy, slog.Default())
+
+		err := run(context.Background())
+		assert.EqualError(err, "broken: gave up after 3 restarts: connection refused")
+		assert.Equal(4, runs)
+	})
+
+	t.Run("stops with ctx", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		run := Supervise("broken", func(ctx context.Context) error {
+			cancel()
+			return errors.New("connection refused")
+		}, RestartPolicy{}, slog.Default())
+
+		assert.NoError(run(ctx))
+	})
+}
@@ -0,0 +1,110 @@
+package goo
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+type SystemdConfig struct {
+	// Notify sends READY=1 and STOPPING=1 notifications, and WATCHDOG=1
+	// keepalives if the unit has WatchdogSec set. Requires Type=notify.
+	Notify bool
+}
+
+// Systemd notifies systemd of the lifecycle of the service. It does nothing if
+// notifications are disabled, or the process isn't run by systemd.
+type Systemd struct {
+	socket string
+	log    *slog.Logger
+}
+
+// ProvideSystemd sends READY=1 once the start functions ran, STOPPING=1 when
+// shutdown begins, and watchdog keepalives in between.
+func ProvideSystemd(cfg *Config, ctx *ShutdownContext, log *slog.Logger) *Systemd {
+	s := &Systemd{log: log.With("_type", "Systemd")}
+
+	if cfg.Systemd == nil || !cfg.Systemd.Notify {
+		return s
+	}
+
+	s.socket = os.Getenv("NOTIFY_SOCKET")
+	if s.socket == "" {
+		return s
+	}
+
+	ctx.OnStart(func(context.Context) error {
+		s.Notify("READY=1")
+
+		if interval := watchdogInterval(); interval > 0 {
+			go s.watchdog(ctx, interval/2)
+		}
+
+		return nil
+	})
+
+	context.AfterFunc(ctx, func() {
+		s.Notify("STOPPING=1")
+	})
+
+	return s
+}
+
+// Notify sends the state to systemd, e.g. "READY=1" or "STATUS=...".
+func (s *Systemd) Notify(state string) error {
+	if s.socket == "" {
+		return nil
+	}
+
+	socket := s.socket
+	if socket[0] == '@' {
+		// abstract socket
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		s.log.Debug("sd_notify", "state", state, "error", err.Error())
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	if err != nil {
+		s.log.Debug("sd_notify", "state", state, "error", err.Error())
+	}
+
+	return err
+}
+
+func (s *Systemd) watchdog(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Notify("WATCHDOG=1")
+		}
+	}
+}
+
+// watchdogInterval returns the watchdog timeout set by systemd for this
+// process, or 0.
+func watchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+
+	return time.Duration(usec) * time.Microsecond
+}
@@ -0,0 +1,52 @@
+package goo
+
+import (
+	"log/slog"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemd(t *testing.T) {
+	assert := assert.New(t)
+
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	assert.NoError(err)
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", socket)
+	t.Setenv("WATCHDOG_USEC", strconv.Itoa(40_000))
+
+	read := func() string {
+		buf := make([]byte, 256)
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := conn.Read(buf)
+		assert.NoError(err)
+		return string(buf[:n])
+	}
+
+	shutdown := NewLifecycle(slog.Default()).ShutdownContext
+
+	ProvideSystemd(&Config{Systemd: &SystemdConfig{Notify: true}}, shutdown, slog.Default())
+	assert.NoError(shutdown.Boot())
+
+	assert.Equal("READY=1", read())
+	assert.Equal("WATCHDOG=1", read())
+
+	shutdown.Shutdown()
+	for {
+		if state := read(); state != "WATCHDOG=1" {
+			assert.Equal("STOPPING=1", state)
+			break
+		}
+	}
+
+	// disabled
+	s := ProvideSystemd(&Config{}, shutdown, slog.Default())
+	assert.NoError(s.Notify("READY=1"))
+}
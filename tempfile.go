@@ -0,0 +1,59 @@
+package goo
+
+import "os"
+
+// TempDir creates a directory in os.TempDir, removed by an exit function. The
+// pattern is as for os.MkdirTemp.
+func (c *ShutdownContext) TempDir(pattern string) (string, error) {
+	dir, err := os.MkdirTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+
+	c.removeOnExit(dir)
+	return dir, nil
+}
+
+// TempFile creates a file in os.TempDir, closed and removed by an exit
+// function. The pattern is as for os.CreateTemp.
+func (c *ShutdownContext) TempFile(pattern string) (*os.File, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	c.OnExitWithPriority(ExitPhaseClose, func() error {
+		f.Close()
+		return nil
+	})
+	c.removeOnExit(f.Name())
+
+	return f, nil
+}
+
+func (c *ShutdownContext) removeOnExit(path string) {
+	c.OnExitWithPriority(ExitPhaseClose, func() error {
+		c.logger.Debug("removing temp file", "path", path)
+		return os.RemoveAll(path)
+	})
+}
+
+// TempDir creates a directory removed on exit by the process-wide
+// ShutdownContext. Without DI, the directory is not removed.
+func TempDir(pattern string) (string, error) {
+	if exitCtx == nil {
+		return os.MkdirTemp("", pattern)
+	}
+
+	return exitCtx.TempDir(pattern)
+}
+
+// TempFile creates a file removed on exit by the process-wide ShutdownContext.
+// Without DI, the file is not removed.
+func TempFile(pattern string) (*os.File, error) {
+	if exitCtx == nil {
+		return os.CreateTemp("", pattern)
+	}
+
+	return exitCtx.TempFile(pattern)
+}
@@ -0,0 +1,34 @@
+package goo
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTempFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &ShutdownContext{Context: context.Background(), logger: slog.Default()}
+
+	dir, err := c.TempDir("goo-test-*")
+	assert.NoError(err)
+	assert.NoError(os.WriteFile(filepath.Join(dir, "scratch"), []byte("data"), 0o644))
+
+	f, err := c.TempFile("goo-test-*.txt")
+	assert.NoError(err)
+	_, err = f.WriteString("data")
+	assert.NoError(err)
+
+	assert.DirExists(dir)
+	assert.FileExists(f.Name())
+
+	c.runExitFns()
+
+	assert.NoDirExists(dir)
+	assert.NoFileExists(f.Name())
+}
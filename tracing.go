@@ -0,0 +1,136 @@
+package goo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type TracingConfig struct {
+	// Endpoint is the OTLP/HTTP endpoint, e.g. localhost:4318.
+	Endpoint string
+	// Insecure sends traces over HTTP instead of HTTPS.
+	Insecure bool
+	// ServiceName is the service.name of the traces.
+	ServiceName string
+	// SampleRatio is the fraction of traces sampled, from 0 to 1. Defaults
+	// to 1.
+	SampleRatio float64
+}
+
+// ProvideTracer exports traces to the configured OTLP endpoint, and traces
+// the requests of the echo server. It sets the global tracer provider and
+// propagator, which otelfetch.Interceptor uses by default. Buffered spans
+// are flushed on exit.
+//
+// Without a configured endpoint, it returns a no-op provider.
+func ProvideTracer(cfg *Config, ctx *ShutdownContext, e *echo.Echo, log *slog.Logger) (trace.TracerProvider, error) {
+	if cfg.Tracing == nil || cfg.Tracing.Endpoint == "" {
+		return noop.NewTracerProvider(), nil
+	}
+
+	tc := cfg.Tracing
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(tc.Endpoint)}
+	if tc.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("provide tracer: %w", err)
+	}
+
+	ratio := tc.SampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(tc.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("provide tracer: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	e.Use(TracingMiddleware(tp))
+
+	ctx.OnExitContextWithPriority(ExitPhaseFlush, func(ctx context.Context) error {
+		return tp.Shutdown(ctx)
+	})
+
+	log.Debug("tracing enabled", "endpoint", tc.Endpoint, "sample_ratio", ratio)
+
+	return tp, nil
+}
+
+// TracingMiddleware starts a server span for every request, continuing the
+// trace propagated by the client.
+func TracingMiddleware(tp trace.TracerProvider) echo.MiddlewareFunc {
+	tracer := tp.Tracer("github.com/hayeah/goo")
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			route := c.Path()
+			ctx, span := tracer.Start(ctx, req.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(req.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(req.URL.Path),
+				),
+			)
+			defer span.End()
+
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+
+			code := c.Response().Status
+			var he *echo.HTTPError
+			if errors.As(err, &he) {
+				code = he.Code
+			} else if err != nil {
+				code = http.StatusInternalServerError
+			}
+
+			span.SetAttributes(attribute.Int(string(semconv.HTTPResponseStatusCodeKey), code))
+			if err != nil {
+				span.RecordError(err)
+			}
+			if code >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(code))
+			}
+
+			return err
+		}
+	}
+}
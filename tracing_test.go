@@ -0,0 +1,58 @@
+package goo_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hayeah/goo"
+	"github.com/hayeah/goo/fetch"
+	"github.com/hayeah/goo/fetch/otelfetch"
+)
+
+func TestTracing(t *testing.T) {
+	assert := assert.New(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	propagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() { otel.SetTextMapPropagator(propagator) })
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	e := echo.New()
+	e.Use(goo.TracingMiddleware(tp))
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]any{"id": c.Param("id")})
+	})
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	_, err := fetch.JSON(http.MethodGet, "/users/1", &fetch.Options{
+		BaseURL:      server.URL,
+		Interceptors: []fetch.Interceptor{otelfetch.Interceptor(tp)},
+	})
+	assert.NoError(err)
+
+	spans := recorder.Ended()
+	if !assert.Len(spans, 2) {
+		return
+	}
+
+	serverSpan, clientSpan := spans[0], spans[1]
+	assert.Equal("GET /users/:id", serverSpan.Name())
+	assert.Equal(trace.SpanKindServer, serverSpan.SpanKind())
+	assert.Equal(trace.SpanKindClient, clientSpan.SpanKind())
+
+	// the server continues the trace of the client
+	assert.Equal(clientSpan.SpanContext().TraceID(), serverSpan.SpanContext().TraceID())
+	assert.Equal(clientSpan.SpanContext().SpanID(), serverSpan.Parent().SpanID())
+}
@@ -0,0 +1,100 @@
+package goo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var configValidator = newConfigValidator()
+
+func newConfigValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// validate the config types as their underlying values, e.g.
+	// `validate:"min=1s"` or `validate:"required,url"`
+	v.RegisterCustomTypeFunc(func(v reflect.Value) any {
+		switch v := v.Interface().(type) {
+		case Duration:
+			return time.Duration(v)
+		case ByteSize:
+			return int64(v)
+		case URL:
+			return v.String()
+		}
+		return nil
+	}, Duration(0), ByteSize(0), URL{})
+
+	return v
+}
+
+// ValidationError reports the config fields that failed validation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// FieldError is a field that failed a `validate` tag rule.
+type FieldError struct {
+	// Field is the path of the field, e.g. Database.DSN.
+	Field string
+	// Rule is the failed rule, e.g. required or oneof=json console.
+	Rule  string
+	Value any
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid config:")
+
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, "\n  %s: failed %s (value: %v)", f.Field, f.Rule, f.Value)
+	}
+
+	return b.String()
+}
+
+// ValidateConfig checks the config against the `validate` tags of its fields,
+// e.g. `validate:"required,url"`. See github.com/go-playground/validator for
+// the rules. ParseConfig validates the config it returns. Configs that aren't
+// structs, e.g. map[string]any, have no rules.
+func ValidateConfig(o any) error {
+	v := reflect.ValueOf(o)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := configValidator.Struct(o)
+	if err == nil {
+		return nil
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+
+	verr := &ValidationError{}
+	for _, ferr := range verrs {
+		rule := ferr.Tag()
+		if ferr.Param() != "" {
+			rule += "=" + ferr.Param()
+		}
+
+		// strip the name of the root struct, e.g. Config.Database.DSN
+		field := ferr.Namespace()
+		if _, rest, ok := strings.Cut(field, "."); ok {
+			field = rest
+		}
+
+		verr.Fields = append(verr.Fields, FieldError{Field: field, Rule: rule, Value: ferr.Value()})
+	}
+
+	return verr
+}
@@ -0,0 +1,71 @@
+package goo
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// WorkerPool runs tasks in the background with bounded concurrency. Exit waits
+// for the running tasks to finish before running the exit functions, so tasks
+// are not killed mid-work.
+type WorkerPool struct {
+	ctx *ShutdownContext
+	log *slog.Logger
+	sem chan struct{}
+	wg  sync.WaitGroup
+}
+
+// NewWorkerPool creates a pool running at most concurrency tasks at a time.
+func NewWorkerPool(ctx *ShutdownContext, log *slog.Logger, concurrency int) *WorkerPool {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	return &WorkerPool{
+		ctx: ctx,
+		log: log.With("_type", "WorkerPool"),
+		sem: make(chan struct{}, concurrency),
+	}
+}
+
+// Submit starts the task in the background, waiting for a free slot if the
+// pool is full. It returns ErrShutdown if the process is shutting down. ctx
+// is done when shutdown begins, but exit waits for the task to return either
+// way. Task errors are logged.
+func (p *WorkerPool) Submit(task func(ctx context.Context) error) error {
+	select {
+	case p.sem <- struct{}{}:
+	case <-p.ctx.Done():
+		return ErrShutdown
+	}
+
+	p.wg.Add(1)
+	started := make(chan error, 1)
+
+	go func() {
+		defer p.wg.Done()
+		defer func() { <-p.sem }()
+
+		err := p.ctx.BlockExit(func() error {
+			started <- nil
+			return task(p.ctx)
+		})
+
+		if err == ErrShutdown {
+			started <- err
+			return
+		}
+
+		if err != nil {
+			p.log.Error("task failed", "error", err.Error())
+		}
+	}()
+
+	return <-started
+}
+
+// Wait waits for the submitted tasks to finish.
+func (p *WorkerPool) Wait() {
+	p.wg.Wait()
+}
@@ -0,0 +1,48 @@
+package goo
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool(t *testing.T) {
+	assert := assert.New(t)
+
+	shutdown := NewLifecycle(slog.Default()).ShutdownContext
+
+	pool := NewWorkerPool(shutdown, slog.Default(), 2)
+
+	var running, maxRunning, done int32
+	for i := 0; i < 6; i++ {
+		err := pool.Submit(func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&done, 1)
+			return nil
+		})
+		assert.NoError(err)
+	}
+
+	// shutdown waits for the running tasks
+	shutdown.Shutdown()
+	shutdown.waitBlocks()
+	pool.Wait()
+
+	assert.Equal(int32(2), maxRunning)
+	assert.Equal(int32(6), done)
+
+	assert.ErrorIs(pool.Submit(func(ctx context.Context) error { return nil }), ErrShutdown)
+}